@@ -0,0 +1,43 @@
+// Package atomicfile writes files so that a crash never leaves them half-written.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Write writes data to a temporary file next to filePath and renames it over filePath, so the file is
+// either left as it was or fully written. The mode of an existing file is kept.
+func Write(filePath string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filePath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write file %s: %v", filePath, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write file %s: %v", filePath, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write file %s: %v", filePath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write file %s: %v", filePath, err)
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return fmt.Errorf("write file %s: %v", filePath, err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return fmt.Errorf("write file %s: %v", filePath, err)
+	}
+	return nil
+}
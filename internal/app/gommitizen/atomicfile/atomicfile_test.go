@@ -0,0 +1,49 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dirPath := t.TempDir()
+	filePath := filepath.Join(dirPath, "run.sh")
+	if err := os.WriteFile(filePath, []byte("VERSION=1.0.0\n"), 0755); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := Write(filePath, []byte("VERSION=1.1.0\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(data) != "VERSION=1.1.0\n" {
+		t.Errorf("expected new content, got %q", data)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("expected mode 0755, got %v", info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary file left, got %d entries", len(entries))
+	}
+
+	newFilePath := filepath.Join(dirPath, "CHANGELOG.md")
+	if err := Write(newFilePath, []byte("# Changelog\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if info, err := os.Stat(newFilePath); err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("expected a new file with mode 0644, got %v, error = %v", info, err)
+	}
+}
@@ -1,15 +1,27 @@
 package bumpmanager
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver"
 
 	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
 )
 
-func IncrementVersion(currentVersionStr string, incType string) (string, string, error) {
+// ValidPrereleases are the prerelease labels accepted by IncrementVersion.
+var ValidPrereleases = []string{"alpha", "beta", "rc"}
+
+// IncrementVersion applies incType to the current version. When prerelease is set, the result is a prerelease of the
+// next version (1.2.3 -> 1.3.0-rc.0), and a later prerelease of the same label only increments its number
+// (1.3.0-rc.0 -> 1.3.0-rc.1). Without prerelease, a prerelease version is promoted to its final version
+// (1.3.0-rc.2 -> 1.3.0) unless the increment goes beyond what the prerelease already covers. A prerelease label that
+// sorts before the current one for the same version (1.3.0-rc.2 -> 1.3.0-beta.0) is an error, the new version would
+// be lower than the current one.
+func IncrementVersion(currentVersionStr string, incType string, prerelease string) (string, string, error) {
 	currentVersion, err := semver.NewVersion(currentVersionStr)
 	if err != nil {
 		return "", "", err
@@ -19,49 +31,161 @@ func IncrementVersion(currentVersionStr string, incType string) (string, string,
 	var newVersion semver.Version
 	if incType == "major" {
 		newVersionStr = "Major"
-		newVersion = currentVersion.IncMajor() // Increment the major (for example, from 1.2.3 to 2.0.0)
+		newVersion = incMajor(*currentVersion) // Increment the major (for example, from 1.2.3 to 2.0.0)
 	} else if incType == "minor" {
 		newVersionStr = "Minor"
-		newVersion = currentVersion.IncMinor() // Increment the minor (for example, from 1.2.3 to 1.3.0)
+		newVersion = incMinor(*currentVersion) // Increment the minor (for example, from 1.2.3 to 1.3.0)
 	} else if incType == "patch" {
 		newVersionStr = "Patch"
 		newVersion = currentVersion.IncPatch() // Increment the patch (for example, from 1.2.3 to 1.2.4)
 	} else {
-		newVersionStr = ""
-		newVersion = *currentVersion
+		return currentVersion.String(), "", nil
+	}
+
+	if len(prerelease) > 0 {
+		newVersion, err = setPrerelease(*currentVersion, newVersion, prerelease)
+		if err != nil {
+			return "", "", err
+		}
+		newVersionStr += " " + prerelease
 	}
 
 	return newVersion.String(), newVersionStr, nil
 }
 
-func BumpCommitAll(modifiedFiles []string, tagVersions []string) ([]string, error) {
-	if len(modifiedFiles) == 0 && len(tagVersions) == 0 {
-		return []string{"Nothing to commit"}, nil
+// incMajor keeps the major of a prerelease that already is the first of its major (2.0.0-rc.0 -> 2.0.0).
+func incMajor(v semver.Version) semver.Version {
+	if len(v.Prerelease()) > 0 && v.Minor() == 0 && v.Patch() == 0 {
+		return v.IncPatch()
 	}
+	return v.IncMajor()
+}
 
-	for _, filePath := range modifiedFiles {
-		_, err := git.AddFilePath(filePath)
-		if err != nil {
-			return nil, fmt.Errorf("error adding file %s: %v", filePath, err)
+// incMinor keeps the minor of a prerelease that already is the first of its minor (1.3.0-rc.0 -> 1.3.0).
+func incMinor(v semver.Version) semver.Version {
+	if len(v.Prerelease()) > 0 && v.Patch() == 0 {
+		return v.IncPatch()
+	}
+	return v.IncMinor()
+}
+
+func setPrerelease(current semver.Version, next semver.Version, prerelease string) (semver.Version, error) {
+	number := 0
+
+	currentBase, _ := current.SetPrerelease("")
+	if len(current.Prerelease()) > 0 && currentBase.Equal(&next) {
+		label, currentNumber := splitPrerelease(current.Prerelease())
+		if label == prerelease {
+			number = currentNumber + 1
 		}
 	}
 
-	message := fmt.Sprintf("bump: new version %s", tagVersions[0])
-	if len(tagVersions) > 1 {
-		message = fmt.Sprintf("bump: new versions %s", strings.Join(tagVersions, ", "))
+	newVersion, err := next.SetPrerelease(fmt.Sprintf("%s.%d", prerelease, number))
+	if err != nil {
+		return newVersion, err
 	}
+	if newVersion.LessThan(&current) {
+		return newVersion, fmt.Errorf("prerelease %s is lower than the current version %s, use a later prerelease label or a final release", newVersion.String(), current.String())
+	}
+	return newVersion, nil
+}
 
-	_, err := git.CreateCommit(message)
+// splitPrerelease splits "rc.2" into its label and number. Labels without a number get -1, so the next one is 0.
+func splitPrerelease(prerelease string) (string, int) {
+	index := strings.LastIndex(prerelease, ".")
+	if index == -1 {
+		return prerelease, -1
+	}
+	number, err := strconv.Atoi(prerelease[index+1:])
 	if err != nil {
-		return nil, fmt.Errorf("error committing %s: %v", message, err)
+		return prerelease, -1
+	}
+	return prerelease[:index], number
+}
+
+// CommitHooks are called by BumpCommitAll around the commit and the tags. Nil hooks are skipped.
+type CommitHooks struct {
+	// PreCommit runs once the modified files are staged, before the commit. It does not run when there is nothing to
+	// commit.
+	PreCommit func() error
+	// PostTag runs once every tag exists. Its error is a released StepError: the bump is kept as it is.
+	PostTag func() error
+}
+
+// BumpCommitAll stages the modified files and commits them, dated date, then creates the tags, running the hooks around
+// the commit and the tags.
+func BumpCommitAll(repo git.Repository, message string, date time.Time, modifiedFiles []string, tagVersions []string, hooks CommitHooks) ([]string, error) {
+	if len(modifiedFiles) == 0 && len(tagVersions) == 0 {
+		return []string{"Nothing to commit"}, nil
+	}
+
+	// Projects whose version lives only in the tags may have nothing to commit
+	if len(modifiedFiles) > 0 {
+		for _, filePath := range modifiedFiles {
+			_, err := repo.AddFilePath(filePath)
+			if err != nil {
+				return nil, &StepError{Step: "git add", Err: fmt.Errorf("error adding file %s: %v", filePath, err)}
+			}
+		}
+
+		if err := runCommitHook(hooks.PreCommit, "pre_commit hook"); err != nil {
+			return nil, err
+		}
+
+		_, err := repo.CreateCommitAt(message, date)
+		if err != nil {
+			return nil, &StepError{Step: "git commit", Err: fmt.Errorf("error committing %s: %v", message, err)}
+		}
 	}
 
 	for _, tagVersion := range tagVersions {
-		_, err := git.CreateTag(tagVersion)
+		_, err := repo.CreateTag(tagVersion)
 		if err != nil {
-			return nil, fmt.Errorf("error tagging %s: %v", tagVersion, err)
+			return nil, &StepError{Step: "git tag", Err: fmt.Errorf("error tagging %s: %v", tagVersion, err)}
+		}
+	}
+
+	if err := runCommitHook(hooks.PostTag, "post_tag hook"); err != nil {
+		var stepErr *StepError
+		if errors.As(err, &stepErr) {
+			stepErr.Released = true
 		}
+		return nil, err
 	}
 
+	if len(modifiedFiles) == 0 {
+		return []string{"Tags created"}, nil
+	}
 	return []string{"Files added and committed"}, nil
 }
+
+// runCommitHook runs a hook of BumpCommitAll. Errors that already tell their step are returned as they are.
+func runCommitHook(hook func() error, step string) error {
+	if hook == nil {
+		return nil
+	}
+	err := hook()
+	if err == nil {
+		return nil
+	}
+	var stepErr *StepError
+	if errors.As(err, &stepErr) {
+		return err
+	}
+	return &StepError{Step: step, Err: err}
+}
+
+// BumpCommitMessage returns the message of the commit that records the given tags. A non-empty template is used
+// instead of the default message, with {tags} replaced by the tags.
+func BumpCommitMessage(template string, tagVersions []string) string {
+	if len(template) > 0 {
+		return strings.ReplaceAll(template, "{tags}", strings.Join(tagVersions, ", "))
+	}
+	if len(tagVersions) == 0 {
+		return "bump: no new versions"
+	}
+	if len(tagVersions) > 1 {
+		return fmt.Sprintf("bump: new versions %s", strings.Join(tagVersions, ", "))
+	}
+	return fmt.Sprintf("bump: new version %s", tagVersions[0])
+}
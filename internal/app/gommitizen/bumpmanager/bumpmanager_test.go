@@ -0,0 +1,71 @@
+package bumpmanager
+
+import (
+	"testing"
+)
+
+func TestIncrementVersion(t *testing.T) {
+	tests := []struct {
+		current    string
+		incType    string
+		prerelease string
+		want       string
+	}{
+		{"1.2.3", "major", "", "2.0.0"},
+		{"1.2.3", "minor", "", "1.3.0"},
+		{"1.2.3", "patch", "", "1.2.4"},
+		{"1.2.3", "none", "", "1.2.3"},
+		{"1.2.3", "minor", "rc", "1.3.0-rc.0"},
+		{"1.3.0-rc.0", "patch", "rc", "1.3.0-rc.1"},
+		{"1.3.0-rc.1", "minor", "rc", "1.3.0-rc.2"},
+		{"1.3.0-rc.2", "major", "rc", "2.0.0-rc.0"},
+		{"1.3.0-alpha.3", "patch", "beta", "1.3.0-beta.0"},
+		{"1.3.0-rc.2", "minor", "", "1.3.0"},
+		{"1.3.0-rc.2", "patch", "", "1.3.0"},
+		{"1.3.0-rc.2", "major", "", "2.0.0"},
+		{"1.2.4-rc.0", "minor", "", "1.3.0"},
+		{"2.0.0-beta.1", "major", "", "2.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"/"+tt.incType+"/"+tt.prerelease, func(t *testing.T) {
+			got, _, err := IncrementVersion(tt.current, tt.incType, tt.prerelease)
+			if err != nil {
+				t.Fatalf("IncrementVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IncrementVersion() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncrementVersionLowerPrerelease(t *testing.T) {
+	for _, tt := range []struct{ current, prerelease string }{{"1.3.0-rc.2", "beta"}, {"1.3.0-beta.0", "alpha"}} {
+		if got, _, err := IncrementVersion(tt.current, "patch", tt.prerelease); err == nil {
+			t.Errorf("expected an error for %s after %s, got %s", tt.prerelease, tt.current, got)
+		}
+	}
+	if _, _, err := IncrementVersion("1.3.0-rc.2", "major", "beta"); err != nil {
+		t.Errorf("expected a beta of the next major to be allowed, got %v", err)
+	}
+}
+
+func TestBumpCommitMessage(t *testing.T) {
+	tests := []struct {
+		template string
+		tags     []string
+		want     string
+	}{
+		{"", []string{"1.2.0+api"}, "bump: new version 1.2.0+api"},
+		{"", []string{"1.2.0+api", "0.3.0+web"}, "bump: new versions 1.2.0+api, 0.3.0+web"},
+		{"", nil, "bump: no new versions"},
+		{"chore(release): {tags}", []string{"1.2.0+api"}, "chore(release): 1.2.0+api"},
+	}
+
+	for _, tt := range tests {
+		if got := BumpCommitMessage(tt.template, tt.tags); got != tt.want {
+			t.Errorf("BumpCommitMessage(%q, %v): expected %q, got %q", tt.template, tt.tags, tt.want, got)
+		}
+	}
+}
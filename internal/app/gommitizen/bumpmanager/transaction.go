@@ -0,0 +1,205 @@
+package bumpmanager
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/atomicfile"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+// StepError is the error of a bump step, with the project and the step where it happened.
+type StepError struct {
+	Project string
+	Step    string
+	Err     error
+	// Released is set when the step failed once the bump commit and the tags exist. They may already be published,
+	// so the bump must not be rolled back.
+	Released bool
+}
+
+func (e *StepError) Error() string {
+	if len(e.Project) == 0 {
+		return fmt.Sprintf("step %s: %v", e.Step, e.Err)
+	}
+	return fmt.Sprintf("project %s, step %s: %v", e.Project, e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+type fileSnapshot struct {
+	filePath string
+	content  []byte
+	mode     os.FileMode
+	existed  bool
+}
+
+// indexSnapshot is the entry a file had in the index before the bump staged it, nil when it was not in the index.
+type indexSnapshot struct {
+	filePath string
+	entry    *git.IndexEntry
+}
+
+// Transaction is a git.Repository that records what a bump changes, so Rollback can restore the working tree, the
+// index, HEAD and the tags to the state they had when the transaction started. Files must be tracked with Track
+// before they are written. Changes made by hooks outside the tracked files are not restored.
+type Transaction struct {
+	git.Repository
+
+	head      string
+	files     []fileSnapshot
+	tracked   map[string]bool
+	index     []indexSnapshot
+	indexed   map[string]bool
+	tags      []string
+	committed bool
+}
+
+func NewTransaction(repo git.Repository) (*Transaction, error) {
+	head, err := repo.GetLastCommit()
+	if err != nil {
+		return nil, fmt.Errorf("start transaction: %v", err)
+	}
+
+	return &Transaction{
+		Repository: repo,
+		head:       head,
+		files:      make([]fileSnapshot, 0),
+		tracked:    make(map[string]bool),
+		index:      make([]indexSnapshot, 0),
+		indexed:    make(map[string]bool),
+		tags:       make([]string, 0),
+	}, nil
+}
+
+// Track saves the current content of a file before the bump writes it. Tracking a file twice keeps the first
+// content.
+func (t *Transaction) Track(filePath string) error {
+	if t.tracked[filePath] {
+		return nil
+	}
+
+	snapshot := fileSnapshot{filePath: filePath}
+	info, err := os.Stat(filePath)
+	if err == nil {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("track file %s: %v", filePath, err)
+		}
+		snapshot.content = content
+		snapshot.mode = info.Mode().Perm()
+		snapshot.existed = true
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("track file %s: %v", filePath, err)
+	}
+
+	t.files = append(t.files, snapshot)
+	t.tracked[filePath] = true
+	return nil
+}
+
+// AddFilePath saves the entry of the file in the index, with the changes the user may have staged, before staging it.
+func (t *Transaction) AddFilePath(filePath string) (string, error) {
+	if !t.indexed[filePath] {
+		entry, err := t.Repository.GetIndexEntry(filePath)
+		if err != nil {
+			return "", fmt.Errorf("save index entry of %s: %v", filePath, err)
+		}
+		t.index = append(t.index, indexSnapshot{filePath: filePath, entry: entry})
+		t.indexed[filePath] = true
+	}
+	return t.Repository.AddFilePath(filePath)
+}
+
+func (t *Transaction) CreateCommit(message string) (string, error) {
+	output, err := t.Repository.CreateCommit(message)
+	if err == nil {
+		t.committed = true
+	}
+	return output, err
+}
+
+func (t *Transaction) CreateCommitAt(message string, date time.Time) (string, error) {
+	output, err := t.Repository.CreateCommitAt(message, date)
+	if err == nil {
+		t.committed = true
+	}
+	return output, err
+}
+
+func (t *Transaction) CreateTag(tag string) (string, error) {
+	output, err := t.Repository.CreateTag(tag)
+	if err == nil {
+		t.tags = append(t.tags, tag)
+	}
+	return output, err
+}
+
+// Rollback undoes everything recorded by the transaction: it deletes the tags, moves HEAD back, restores the index
+// entries of the staged files and restores their content. It keeps going when a step fails and returns all the errors found.
+func (t *Transaction) Rollback() error {
+	errs := make([]error, 0)
+
+	for i := len(t.tags) - 1; i >= 0; i-- {
+		if _, err := t.Repository.DeleteTag(t.tags[i]); err != nil {
+			errs = append(errs, fmt.Errorf("delete tag %s: %v", t.tags[i], err))
+			continue
+		}
+		slog.Info(fmt.Sprintf("Rollback: deleted tag %s", t.tags[i]))
+	}
+	t.tags = make([]string, 0)
+
+	if t.committed {
+		if _, err := t.Repository.ResetHead(t.head); err != nil {
+			errs = append(errs, fmt.Errorf("reset to %s: %v", t.head, err))
+		} else {
+			slog.Info(fmt.Sprintf("Rollback: reset HEAD to %s", t.head))
+			t.committed = false
+		}
+	}
+
+	for i := len(t.index) - 1; i >= 0; i-- {
+		snapshot := t.index[i]
+		if _, err := t.Repository.RestoreIndexEntry(snapshot.filePath, snapshot.entry); err != nil {
+			errs = append(errs, fmt.Errorf("restore index entry of %s: %v", snapshot.filePath, err))
+		}
+	}
+	t.index = make([]indexSnapshot, 0)
+	t.indexed = make(map[string]bool)
+
+	for i := len(t.files) - 1; i >= 0; i-- {
+		snapshot := t.files[i]
+		if err := snapshot.restore(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if snapshot.existed {
+			slog.Info(fmt.Sprintf("Rollback: restored %s", snapshot.filePath))
+		} else {
+			slog.Info(fmt.Sprintf("Rollback: removed %s", snapshot.filePath))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s fileSnapshot) restore() error {
+	if !s.existed {
+		if err := os.Remove(s.filePath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove %s: %v", s.filePath, err)
+		}
+		return nil
+	}
+	if err := atomicfile.Write(s.filePath, s.content); err != nil {
+		return fmt.Errorf("restore %s: %v", s.filePath, err)
+	}
+	if err := os.Chmod(s.filePath, s.mode); err != nil {
+		return fmt.Errorf("restore %s: %v", s.filePath, err)
+	}
+	return nil
+}
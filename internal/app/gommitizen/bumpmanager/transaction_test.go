@@ -0,0 +1,153 @@
+package bumpmanager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+func TestTransactionRollback(t *testing.T) {
+	dirPath := t.TempDir()
+	versionPath := filepath.Join(dirPath, ".version.json")
+	changelogPath := filepath.Join(dirPath, "CHANGELOG.md")
+	if err := os.WriteFile(versionPath, []byte(`{"version": "1.0.0"}`), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	repo := git.NewFakeRepository()
+	head := repo.AddCommit("chore: init", versionPath)
+	repo.Tags["1.1.0+b"] = head
+
+	tx, err := NewTransaction(repo)
+	if err != nil {
+		t.Fatalf("NewTransaction() error = %v", err)
+	}
+	for _, filePath := range []string{versionPath, changelogPath} {
+		if err := tx.Track(filePath); err != nil {
+			t.Fatalf("Track() error = %v", err)
+		}
+	}
+	if err := os.WriteFile(versionPath, []byte(`{"version": "1.1.0"}`), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(changelogPath, []byte("# 1.1.0\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	// The second tag already exists, so tagging fails after the commit and the first tag
+	_, err = BumpCommitAll(tx, BumpCommitMessage("", []string{"1.1.0+a", "1.1.0+b"}), time.Now(), []string{versionPath, changelogPath}, []string{"1.1.0+a", "1.1.0+b"}, CommitHooks{})
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != "git tag" {
+		t.Fatalf("expected a git tag step error, got %v", err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	if last, _ := repo.GetLastCommit(); last != head {
+		t.Errorf("expected HEAD back at %s, got %s", head, last)
+	}
+	if _, ok := repo.Tags["1.1.0+a"]; ok {
+		t.Errorf("expected tag 1.1.0+a to be deleted")
+	}
+	if _, ok := repo.Tags["1.1.0+b"]; !ok {
+		t.Errorf("expected pre-existing tag 1.1.0+b to be kept")
+	}
+	if len(repo.Staged) != 0 {
+		t.Errorf("expected nothing staged, got %v", repo.Staged)
+	}
+	data, _ := os.ReadFile(versionPath)
+	if string(data) != `{"version": "1.0.0"}` {
+		t.Errorf("expected %s restored, got %s", versionPath, string(data))
+	}
+	if _, err := os.Stat(changelogPath); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed", changelogPath)
+	}
+}
+
+func TestTransactionRollbackKeepsStagedChanges(t *testing.T) {
+	dirPath := t.TempDir()
+	versionPath := filepath.Join(dirPath, ".version.json")
+	changelogPath := filepath.Join(dirPath, "CHANGELOG.md")
+	if err := os.WriteFile(versionPath, []byte(`{"version": "1.0.0"}`), 0600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	repo := git.NewFakeRepository()
+	head := repo.AddCommit("chore: init", versionPath)
+	// The user staged the version file before bumping
+	repo.Staged = []string{versionPath}
+
+	tx, err := NewTransaction(repo)
+	if err != nil {
+		t.Fatalf("NewTransaction() error = %v", err)
+	}
+	for _, filePath := range []string{versionPath, changelogPath} {
+		if err := tx.Track(filePath); err != nil {
+			t.Fatalf("Track() error = %v", err)
+		}
+		if err := os.WriteFile(filePath, []byte("bumped"), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if _, err := tx.AddFilePath(filePath); err != nil {
+			t.Fatalf("AddFilePath() error = %v", err)
+		}
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	if last, _ := repo.GetLastCommit(); last != head {
+		t.Errorf("expected HEAD at %s, got %s", head, last)
+	}
+	if !reflect.DeepEqual(repo.Staged, []string{versionPath}) {
+		t.Errorf("expected only the version file staged, got %v", repo.Staged)
+	}
+	info, err := os.Stat(versionPath)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600 restored, got %v", info.Mode().Perm())
+	}
+}
+
+func TestBumpCommitAllHooks(t *testing.T) {
+	repo := git.NewFakeRepository()
+	head := repo.AddCommit("chore: init", "a/.version.json")
+
+	steps := make([]string, 0)
+	hooks := CommitHooks{
+		PreCommit: func() error {
+			last, _ := repo.GetLastCommit()
+			steps = append(steps, fmt.Sprintf("pre_commit staged=%v committed=%t", repo.Staged, last != head))
+			return nil
+		},
+		PostTag: func() error {
+			steps = append(steps, fmt.Sprintf("post_tag tags=%d", len(repo.Tags)))
+			return errors.New("push failed")
+		},
+	}
+
+	_, err := BumpCommitAll(repo, "bump: new version 1.1.0+a", time.Now(), []string{"a/.version.json"}, []string{"1.1.0+a"}, hooks)
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != "post_tag hook" {
+		t.Fatalf("expected a post_tag hook step error, got %v", err)
+	}
+	if !stepErr.Released {
+		t.Errorf("expected the post_tag hook error to keep the released bump")
+	}
+
+	expected := []string{"pre_commit staged=[a/.version.json] committed=false", "post_tag tags=1"}
+	if !reflect.DeepEqual(steps, expected) {
+		t.Errorf("expected %v, got %v", expected, steps)
+	}
+}
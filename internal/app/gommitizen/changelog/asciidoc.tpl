@@ -0,0 +1,22 @@
+{{- define "hash" }}{{ with commitURL .Hash }}{{ . }}[{{ $.ShortHash }}]{{ else }}{{ .ShortHash }}{{ end }}{{ end -}}
+
+== {{ with .CompareURL }}{{ . }}[{{ $.Version }}]{{ else }}{{ .Version }}{{ end }} ({{ .Date }})
+{{- printf "\n" -}}
+
+{{- if .BreakingChanges }}
+=== Breaking changes
+{{ range .BreakingChanges }}
+* {{ if .Scope }}*{{ .Scope }}*: {{ end }}{{ linkReferencesAsciiDoc .BreakingDescription }} ({{ template "hash" . }})
+{{- end }}
+{{- printf "\n" -}}
+{{- end }}
+
+{{- range .Sections }}
+=== {{ .Title }}
+{{ $showType := .ShowType }}
+{{- range .Commits }}
+* {{ if $showType }}{{ .ChangeType }}{{ if .Scope }}(*{{ .Scope }}*): {{ else }}: {{ end }}{{ else if .Scope }}*{{ .Scope }}*: {{ end }}{{ linkReferencesAsciiDoc .Subject }} ({{ template "hash" . }})
+{{- end }}
+{{- printf "\n" -}}
+{{- end }}
+{{- printf "\n" -}}
\ No newline at end of file
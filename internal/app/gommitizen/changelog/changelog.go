@@ -4,84 +4,394 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
-	"html/template"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
 	"time"
 
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/atomicfile"
 	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
 )
 
+// data is what a changelog template renders for a version section.
 type data struct {
-	Version string
+	Version         string
+	PreviousVersion string
+	Tag             string
+	PreviousTag     string
+	// Date of the section as YYYY-MM-DD, Time to format it otherwise with the date helper
 	Date    string
+	Time    time.Time
+	RepoURL string
+	// CompareURL is the page comparing the previous tag with the tag of the section, empty without links
+	CompareURL string
 
+	// Commits holds every commit of the section, hidden change types included
+	Commits         []conventionalcommits.CommitData
 	BreakingChanges []conventionalcommits.CommitData
-	Features        []conventionalcommits.CommitData
-	BugFixes        []conventionalcommits.CommitData
-	Refactors       []conventionalcommits.CommitData
-	Miscellaneous   []conventionalcommits.CommitData
+	// Sections holds the commits of each change type shown in the changelog, in section order, and Groups the commits
+	// of every change type by section name
+	Sections []section
+	Groups   map[string][]conventionalcommits.CommitData
+}
+
+// section is the list of the commits of a change type.
+type section struct {
+	Title    string
+	ShowType bool
+	Commits  []conventionalcommits.CommitData
 }
 
 const changelogFileName = "CHANGELOG.md"
 
-//go:embed template.tpl
+// Unreleased is the title of the section of the changes made since the last release.
+const Unreleased = "Unreleased"
+
+// versionHeaderRegex matches the headers of the version sections of a changelog, like `# 1.2.0 (2024-01-31)`,
+// `# [1.2.0](https://...) (2024-01-31)` or `## [Unreleased]`, and captures the version.
+var versionHeaderRegex = regexp.MustCompile(`^#{1,2} \[?(Unreleased|v?[0-9]+\.[0-9]+\.[0-9]+[^\s\]]*)(\]\([^)\s]*\))?\]?(\s|$)`)
+
+// Options tells how a changelog section is rendered and written.
+type Options struct {
+	// Date of the section, today when zero
+	Date time.Time
+	// PreviousVersion, Tag and PreviousTag of the version of the section, when known
+	PreviousVersion string
+	Tag             string
+	PreviousTag     string
+	// Links to the web pages of the repository, none when empty
+	Links Links
+	// Format of the changelog, Markdown when empty
+	Format string
+	// TemplatePath is the text/template file a Markdown section is rendered with, the built-in template when empty
+	TemplatePath string
+	// Incremental inserts the section under the title of the file, before the first version section, instead of at
+	// the top of the file.
+	Incremental bool
+}
+
+//go:embed template.tpl root.tpl keepachangelog.tpl asciidoc.tpl
 var tplFile embed.FS
 
-func Apply(dirPath string, version string, commits []conventionalcommits.CommitData) (string, error) {
-	changelogFilePath := filepath.Join(dirPath, changelogFileName)
-	groupByCommonChangeType := groupByCommonChangeType(commits)
+// templateFuncs are the helper functions of changelog templates, on top of the text/template built-ins.
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"capitalize": func(s string) string {
+		if len(s) == 0 {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+	"trim":      strings.TrimSpace,
+	"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"join":      func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	"indent": func(spaces int, s string) string {
+		pad := strings.Repeat(" ", spaces)
+		return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+	},
+	"date": func(layout string, t time.Time) string { return t.Format(layout) },
+	"default": func(def, s string) string {
+		if len(s) == 0 {
+			return def
+		}
+		return s
+	},
+	"keepAChangelogSection": func(title string) string {
+		if name, ok := keepAChangelogSections[title]; ok {
+			return name
+		}
+		return title
+	},
+}
 
-	data := data{
-		Version: version,
-		Date:    time.Now().Format("2006-01-02"),
+// Apply writes the changelog section of a version to the changelog file of the format of opts, and returns the file.
+// The section of the same version is replaced, and the Unreleased section is dropped once a release is written, its
+// changes being part of the release.
+func Apply(dirPath string, version string, commits []conventionalcommits.CommitData, changeTypes conventionalcommits.ChangeTypes, opts Options) (string, error) {
+	changelogFilePath := GetFormatFilePath(dirPath, opts.Format)
+
+	if opts.Format == FormatJSON {
+		release := newJSONRelease(newData(version, commits, changeTypes, opts), opts.Links)
+		if err := upsertJSONFile(changelogFilePath, release); err != nil {
+			return "", fmt.Errorf("fail to update file: %v", err)
+		}
+		return changelogFilePath, nil
+	}
 
-		BreakingChanges: groupByCommonChangeType[conventionalcommits.CommonNameBC],
-		Features:        groupByCommonChangeType[conventionalcommits.CommonNameFeat],
-		BugFixes:        groupByCommonChangeType[conventionalcommits.CommonNameFix],
-		Refactors:       groupByCommonChangeType[conventionalcommits.CommonNameRefactor],
-		Miscellaneous:   groupByCommonChangeType[conventionalcommits.CommonNameMiscellaneous],
+	section, err := Render(version, commits, changeTypes, opts)
+	if err != nil {
+		return "", err
 	}
 
-	tpl, err := template.ParseFS(tplFile, "template.tpl")
+	err = writeSection(changelogFilePath, version, section, getFormat(opts.Format).headerRegex, opts.Incremental)
+	if err != nil {
+		return "", fmt.Errorf("fail to update file: %v", err)
+	}
+
+	return changelogFilePath, nil
+}
+
+// Render returns the changelog section of a version without writing it. Commits are listed in the sections of their
+// change types, in section order, hidden change types are left out.
+func Render(version string, commits []conventionalcommits.CommitData, changeTypes conventionalcommits.ChangeTypes, opts Options) (string, error) {
+	if opts.Format == FormatJSON {
+		return marshalJSON(newJSONRelease(newData(version, commits, changeTypes, opts), opts.Links))
+	}
+
+	templatePath := ""
+	if len(opts.Format) == 0 || opts.Format == FormatMarkdown {
+		templatePath = opts.TemplatePath
+	}
+	tpl, err := loadTemplate(getFormat(opts.Format).template, templatePath, opts.Links)
 	if err != nil {
 		return "", fmt.Errorf("fail to load template: %v", err)
 	}
 
 	var buf bytes.Buffer
-	err = tpl.Execute(&buf, data)
+	err = tpl.Execute(&buf, newData(version, commits, changeTypes, opts))
 	if err != nil {
 		return "", fmt.Errorf("fail to execute template: %v", err)
 	}
 
-	err = prependToFile(changelogFilePath, buf)
+	return buf.String(), nil
+}
+
+// newData returns what a template renders for the section of a version.
+func newData(version string, commits []conventionalcommits.CommitData, changeTypes conventionalcommits.ChangeTypes, opts Options) data {
+	groupByCommonChangeType := groupByCommonChangeType(commits)
+
+	date := opts.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	data := data{
+		Version:         version,
+		PreviousVersion: opts.PreviousVersion,
+		Tag:             opts.Tag,
+		PreviousTag:     opts.PreviousTag,
+		Date:            date.Format("2006-01-02"),
+		Time:            date,
+		RepoURL:         opts.Links.URL,
+		CompareURL:      opts.Links.CompareURL(opts.PreviousTag, opts.Tag),
+
+		Commits:         commits,
+		BreakingChanges: filterBreakingChanges(commits),
+		Groups:          groupByCommonChangeType,
+	}
+	for _, changeType := range changeTypes.Sections() {
+		sectionCommits := groupByCommonChangeType[changeType.CommonName]
+		if len(sectionCommits) == 0 {
+			continue
+		}
+		data.Sections = append(data.Sections, section{
+			Title:    changeType.CommonName,
+			ShowType: changeType.ShowType,
+			Commits:  sectionCommits,
+		})
+	}
+	return data
+}
+
+// loadTemplate parses the template file at templatePath, or the built-in template named builtin when it is empty. The
+// link helpers of the template render links.
+func loadTemplate(builtin string, templatePath string, links Links) (*template.Template, error) {
+	linkFuncs := template.FuncMap{
+		"commitURL":              links.CommitURL,
+		"compareURL":             links.CompareURL,
+		"linkReferences":         links.LinkReferences,
+		"linkReferencesAsciiDoc": links.LinkReferencesAsciiDoc,
+	}
+
+	if len(templatePath) == 0 {
+		return template.New(builtin).Funcs(templateFuncs).Funcs(linkFuncs).ParseFS(tplFile, builtin)
+	}
+	return template.New(filepath.Base(templatePath)).Funcs(templateFuncs).Funcs(linkFuncs).ParseFiles(templatePath)
+}
+
+// Release is a version of a project with its date, its tag, the version and tag it follows and the commits it released.
+type Release struct {
+	Version         string
+	PreviousVersion string
+	Tag             string
+	PreviousTag     string
+	Date            time.Time
+	Commits         []conventionalcommits.CommitData
+}
+
+// RenderReleases returns the changelog sections of releases, given oldest first, with the newest release on top. The
+// date, tags and previous version of opts are taken from each release. In JSON, it is the whole changelog.
+func RenderReleases(releases []Release, changeTypes conventionalcommits.ChangeTypes, opts Options) (string, error) {
+	jsonReleases := make([]jsonRelease, 0, len(releases))
+	var buf bytes.Buffer
+	for i := len(releases) - 1; i >= 0; i-- {
+		release := releases[i]
+		opts.Date, opts.PreviousVersion = release.Date, release.PreviousVersion
+		opts.Tag, opts.PreviousTag = release.Tag, release.PreviousTag
+		if opts.Format == FormatJSON {
+			jsonReleases = append(jsonReleases, newJSONRelease(newData(release.Version, release.Commits, changeTypes, opts), opts.Links))
+			continue
+		}
+
+		section, err := Render(release.Version, release.Commits, changeTypes, opts)
+		if err != nil {
+			return "", fmt.Errorf("render %s: %v", release.Version, err)
+		}
+		buf.WriteString(section)
+	}
+
+	if opts.Format == FormatJSON {
+		return marshalJSON(jsonReleases)
+	}
+	return buf.String(), nil
+}
+
+// Rebuild replaces the version sections of the changelog file of the project in dirPath with a section per release.
+// The title of the file, everything before its first version section, is kept. It returns the changelog file.
+func Rebuild(dirPath string, releases []Release, changeTypes conventionalcommits.ChangeTypes, opts Options) (string, error) {
+	changelogFilePath := GetFormatFilePath(dirPath, opts.Format)
+
+	sections, err := RenderReleases(releases, changeTypes, opts)
 	if err != nil {
-		return "", fmt.Errorf("fail to prepend to file: %v", err)
+		return "", err
+	}
+
+	content := []byte(sections)
+	if opts.Format != FormatJSON {
+		existingContent, err := os.ReadFile(changelogFilePath)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read file: %v", err)
+		}
+		content = replaceSections(existingContent, sections, getFormat(opts.Format).headerRegex)
+	}
+
+	err = atomicfile.Write(changelogFilePath, content)
+	if err != nil {
+		return "", fmt.Errorf("failed to write to file: %v", err)
 	}
 
 	return changelogFilePath, nil
 }
 
+// replaceSections returns content with everything from its first version section on replaced by sections. A content
+// without version sections is all title, sections are appended to it.
+func replaceSections(content []byte, sections string, headerRegex *regexp.Regexp) []byte {
+	offset := 0
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if headerRegex.Match(bytes.TrimRight(line, "\r\n")) {
+			return append(append([]byte{}, content[:offset]...), sections...)
+		}
+		offset += len(line)
+	}
+	return insertSection(content, "", sections, headerRegex)
+}
+
 func prependToFile(changelogFilePath string, data bytes.Buffer) error {
 	existingContent, err := os.ReadFile(changelogFilePath)
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to read file: %v", err)
 	}
 
-	outFile, err := os.OpenFile(changelogFilePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	err = atomicfile.Write(changelogFilePath, append(data.Bytes(), existingContent...))
 	if err != nil {
-		return fmt.Errorf("failed to open or create file: %v", err)
+		return fmt.Errorf("failed to write to file: %v", err)
 	}
-	defer outFile.Close()
 
-	_, err = outFile.Write(append(data.Bytes(), existingContent...))
-	if err != nil {
-		return fmt.Errorf("failed to write to file: %v", err)
+	return nil
+}
+
+// writeSection writes the section of version to the changelog file. An incremental section goes under the title of
+// the file, otherwise at the top of the file, and both replace the section of the same version. Writing a release
+// drops the Unreleased section.
+func writeSection(changelogFilePath string, version string, section string, headerRegex *regexp.Regexp, incremental bool) error {
+	existingContent, err := os.ReadFile(changelogFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read file: %v", err)
 	}
 
+	if version != Unreleased {
+		existingContent = removeSection(existingContent, Unreleased, headerRegex)
+	}
+	var content []byte
+	if incremental {
+		content = insertSection(existingContent, version, section, headerRegex)
+	} else {
+		content = append([]byte(section), removeSection(existingContent, version, headerRegex)...)
+	}
+
+	if err := atomicfile.Write(changelogFilePath, content); err != nil {
+		return fmt.Errorf("failed to write to file: %v", err)
+	}
 	return nil
 }
 
+// findSection returns the offsets of the first version section of content and of the start and end of the section of
+// version, -1 when there is none.
+func findSection(content []byte, version string, headerRegex *regexp.Regexp) (int, int, int) {
+	first, start, end := -1, -1, -1
+	offset := 0
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if match := headerRegex.FindSubmatch(bytes.TrimRight(line, "\r\n")); match != nil {
+			if first == -1 {
+				first = offset
+			}
+			if start != -1 && end == -1 {
+				end = offset
+			}
+			if start == -1 && string(match[1]) == version {
+				start = offset
+			}
+		}
+		offset += len(line)
+	}
+	if start != -1 && end == -1 {
+		end = len(content)
+	}
+	return first, start, end
+}
+
+// removeSection returns content without the section of version.
+func removeSection(content []byte, version string, headerRegex *regexp.Regexp) []byte {
+	_, start, end := findSection(content, version, headerRegex)
+	if start == -1 {
+		return content
+	}
+	return append(append([]byte{}, content[:start]...), content[end:]...)
+}
+
+// insertSection returns content with section in place of the section of version, or else before the first version
+// section, or else at the end.
+func insertSection(content []byte, version string, section string, headerRegex *regexp.Regexp) []byte {
+	first, start, end := findSection(content, version, headerRegex)
+
+	result := make([]byte, 0, len(content)+len(section)+1)
+	switch {
+	case start != -1:
+		result = append(result, content[:start]...)
+		result = append(result, section...)
+		result = append(result, content[end:]...)
+	case first != -1:
+		result = append(result, content[:first]...)
+		result = append(result, section...)
+		result = append(result, content[first:]...)
+	default:
+		result = append(result, content...)
+		if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n\n")) {
+			if !bytes.HasSuffix(content, []byte("\n")) {
+				result = append(result, '\n')
+			}
+			result = append(result, '\n')
+		}
+		result = append(result, section...)
+	}
+	return result
+}
+
 func groupByCommonChangeType(commits []conventionalcommits.CommitData) map[string][]conventionalcommits.CommitData {
 	groups := make(map[string][]conventionalcommits.CommitData)
 
@@ -92,3 +402,15 @@ func groupByCommonChangeType(commits []conventionalcommits.CommitData) map[strin
 
 	return groups
 }
+
+func filterBreakingChanges(commits []conventionalcommits.CommitData) []conventionalcommits.CommitData {
+	breaking := make([]conventionalcommits.CommitData, 0)
+
+	for _, item := range commits {
+		if item.Breaking {
+			breaking = append(breaking, item)
+		}
+	}
+
+	return breaking
+}
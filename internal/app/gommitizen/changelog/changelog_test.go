@@ -0,0 +1,235 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
+)
+
+func TestRenderSections(t *testing.T) {
+	changeTypes := conventionalcommits.ChangeTypes{
+		{Order: 1, CommonName: "Fixes", Prefixes: []string{"fix"}, Increment: conventionalcommits.IncrementPatch},
+		{Order: 0, CommonName: "Dependencies", Prefixes: []string{"deps"}, Increment: conventionalcommits.IncrementPatch},
+		{Order: 2, CommonName: "Chores", Prefixes: []string{"chore"}, Increment: conventionalcommits.IncrementNone, Hidden: true},
+	}
+	commits := []conventionalcommits.CommitData{
+		{ShortHash: "aaaaaaa", CommonChangeType: "Fixes", ChangeType: "fix", Scope: "api", Subject: "handle nil"},
+		{ShortHash: "bbbbbbb", CommonChangeType: "Dependencies", ChangeType: "deps", Subject: "bump yaml"},
+		{ShortHash: "ccccccc", CommonChangeType: "Chores", ChangeType: "chore", Subject: "tidy"},
+	}
+
+	section, err := Render("1.1.0", commits, changeTypes, Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	dependencies := strings.Index(section, "## Dependencies\n- bump yaml (#bbbbbbb)")
+	fixes := strings.Index(section, "## Fixes\n- **api**: handle nil (#aaaaaaa)")
+	if dependencies == -1 || fixes == -1 || dependencies > fixes {
+		t.Errorf("expected Dependencies then Fixes sections, got:\n%s", section)
+	}
+	if strings.Contains(section, "Chores") || strings.Contains(section, "tidy") {
+		t.Errorf("expected hidden section to be left out, got:\n%s", section)
+	}
+}
+
+func TestRenderDoesNotEscape(t *testing.T) {
+	commits := []conventionalcommits.CommitData{
+		{ShortHash: "aaaaaaa", CommonChangeType: "Fixes", ChangeType: "fix", Subject: `handle "a & b" in <Header>`},
+	}
+
+	section, err := Render("1.0.1", commits, conventionalcommits.DefaultChangeTypes(), Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(section, `- handle "a & b" in <Header> (#aaaaaaa)`) {
+		t.Errorf("expected the subject as written, got:\n%s", section)
+	}
+}
+
+func TestRenderLinks(t *testing.T) {
+	commits := []conventionalcommits.CommitData{
+		{Hash: "aaaaaaa111", ShortHash: "aaaaaaa", CommonChangeType: "Fixes", ChangeType: "fix", Subject: "handle nil (#7)"},
+	}
+
+	section, err := Render("1.0.1", commits, conventionalcommits.DefaultChangeTypes(), Options{
+		Date:        time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		Tag:         "v1.0.1",
+		PreviousTag: "v1.0.0",
+		Links:       ResolveLinks("git@github.com:owner/repo.git", Links{}),
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	expected := "# [1.0.1](https://github.com/owner/repo/compare/v1.0.0...v1.0.1) (2024-02-01)\n\n## Fixes\n" +
+		"- handle nil ([#7](https://github.com/owner/repo/issues/7)) ([aaaaaaa](https://github.com/owner/repo/commit/aaaaaaa111))\n"
+	if section != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, section)
+	}
+}
+
+func TestRenderCustomTemplate(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "changelog.tpl")
+	tpl := `## [{{ .Version }}]({{ .RepoURL }}/compare/{{ .PreviousVersion }}...{{ .Tag }}) - {{ date "Jan 2, 2006" .Time }}
+{{ range .Sections }}### {{ upper .Title }}
+{{ range .Commits }}* {{ capitalize .Subject }} ({{ default "none" .Scope }})
+{{ end }}{{ end }}{{ len .Commits }} commits, {{ len (index .Groups "Miscellaneous") }} hidden
+`
+	if err := os.WriteFile(templatePath, []byte(tpl), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	changeTypes := conventionalcommits.ChangeTypes{
+		{CommonName: "Features", Prefixes: []string{"feat"}, Increment: conventionalcommits.IncrementMinor},
+		{CommonName: "Miscellaneous", Prefixes: []string{"chore"}, Increment: conventionalcommits.IncrementNone, Hidden: true},
+	}
+	commits := []conventionalcommits.CommitData{
+		{ShortHash: "aaaaaaa", CommonChangeType: "Features", ChangeType: "feat", Scope: "api", Subject: "add endpoint"},
+		{ShortHash: "bbbbbbb", CommonChangeType: "Miscellaneous", ChangeType: "chore", Subject: "tidy"},
+	}
+
+	section, err := Render("1.1.0", commits, changeTypes, Options{
+		Date:            time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		PreviousVersion: "1.0.0",
+		Tag:             "v1.1.0",
+		Links:           Links{URL: "https://github.com/owner/repo"},
+		TemplatePath:    templatePath,
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	expected := `## [1.1.0](https://github.com/owner/repo/compare/1.0.0...v1.1.0) - Feb 1, 2024
+### FEATURES
+* Add endpoint (api)
+2 commits, 1 hidden
+`
+	if section != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, section)
+	}
+
+	if _, err := Render("1.1.0", commits, changeTypes, Options{TemplatePath: templatePath + ".missing"}); err == nil {
+		t.Errorf("expected an error for a missing template")
+	}
+}
+
+func TestInsertSection(t *testing.T) {
+	title := "# Changelog\n\nAll notable changes of the project.\n\n"
+	released := "# 1.0.0 (2024-01-01)\n## Features\n- first (#aaaaaaa)\n\n"
+
+	tests := []struct {
+		name     string
+		content  string
+		version  string
+		section  string
+		expected string
+	}{
+		{
+			name:     "new file",
+			content:  "",
+			version:  Unreleased,
+			section:  "# Unreleased (2024-02-01)\n",
+			expected: "# Unreleased (2024-02-01)\n",
+		},
+		{
+			name:     "under the title",
+			content:  title + released,
+			version:  "1.1.0",
+			section:  "# 1.1.0 (2024-02-01)\n\n",
+			expected: title + "# 1.1.0 (2024-02-01)\n\n" + released,
+		},
+		{
+			name:     "replaces the same version",
+			content:  title + "# Unreleased (2024-01-15)\n## Fixes\n- old (#bbbbbbb)\n\n" + released,
+			version:  Unreleased,
+			section:  "# Unreleased (2024-02-01)\n## Fixes\n- new (#ccccccc)\n\n",
+			expected: title + "# Unreleased (2024-02-01)\n## Fixes\n- new (#ccccccc)\n\n" + released,
+		},
+		{
+			name:     "linked version header",
+			content:  title + "# [1.1.0](https://example.com/compare/1.0.0...1.1.0) (2024-01-15)\n- old\n\n" + released,
+			version:  "1.1.0",
+			section:  "# 1.1.0 (2024-02-01)\n",
+			expected: title + "# 1.1.0 (2024-02-01)\n" + released,
+		},
+		{
+			name:     "title only",
+			content:  "# Changelog\n",
+			version:  Unreleased,
+			section:  "# Unreleased (2024-02-01)\n",
+			expected: "# Changelog\n\n# Unreleased (2024-02-01)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := string(insertSection([]byte(tt.content), tt.version, tt.section, versionHeaderRegex))
+			if result != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestApplyReplacesUnreleased(t *testing.T) {
+	changeTypes := conventionalcommits.DefaultChangeTypes()
+	first := []conventionalcommits.CommitData{{ShortHash: "aaaaaaa", CommonChangeType: "Fixes", ChangeType: "fix", Subject: "first"}}
+	second := []conventionalcommits.CommitData{{ShortHash: "bbbbbbb", CommonChangeType: "Fixes", ChangeType: "fix", Subject: "second"}}
+	date := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, incremental := range []bool{false, true} {
+		dirPath := t.TempDir()
+		opts := Options{Date: date, Incremental: incremental}
+		if err := os.WriteFile(GetFormatFilePath(dirPath, FormatMarkdown), []byte("# 1.0.0 (2024-01-01)\n\n"), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+
+		for _, commits := range [][]conventionalcommits.CommitData{first, second} {
+			if _, err := Apply(dirPath, Unreleased, commits, changeTypes, opts); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+		}
+		content, _ := os.ReadFile(GetFormatFilePath(dirPath, FormatMarkdown))
+		expected := "# Unreleased (2024-02-01)\n\n## Fixes\n- second (#bbbbbbb)\n# 1.0.0 (2024-01-01)\n\n"
+		if string(content) != expected {
+			t.Errorf("incremental %t: expected one Unreleased section:\n%q\ngot:\n%q", incremental, expected, content)
+		}
+
+		if _, err := Apply(dirPath, "1.1.0", second, changeTypes, opts); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		content, _ = os.ReadFile(GetFormatFilePath(dirPath, FormatMarkdown))
+		expected = "# 1.1.0 (2024-02-01)\n\n## Fixes\n- second (#bbbbbbb)\n# 1.0.0 (2024-01-01)\n\n"
+		if string(content) != expected {
+			t.Errorf("incremental %t: expected the release in place of Unreleased:\n%q\ngot:\n%q", incremental, expected, content)
+		}
+	}
+}
+
+func TestReplaceSections(t *testing.T) {
+	title := "# Changelog\n\nAll notable changes of the project.\n\n"
+	sections := "# 1.1.0 (2024-02-01)\n\n# 1.0.0 (2024-01-01)\n\n"
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"new file", "", sections},
+		{"keeps the title", title + "# Unreleased (2024-01-15)\n## Fixes\n- old (#bbbbbbb)\n\n# 1.0.0 (2023-12-01)\n", title + sections},
+		{"title only", "# Changelog\n", "# Changelog\n\n" + sections},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := string(replaceSections([]byte(tt.content), sections, versionHeaderRegex))
+			if result != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, result)
+			}
+		})
+	}
+}
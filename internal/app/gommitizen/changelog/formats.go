@@ -0,0 +1,194 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/atomicfile"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
+)
+
+const (
+	// FormatMarkdown is the default changelog, rendered with the built-in template or with changelog_template
+	FormatMarkdown = "markdown"
+	// FormatKeepAChangelog is a Markdown changelog following https://keepachangelog.com
+	FormatKeepAChangelog = "keepachangelog"
+	// FormatAsciiDoc is an AsciiDoc changelog
+	FormatAsciiDoc = "asciidoc"
+	// FormatJSON is a JSON list of releases, newest first, for tools to ingest
+	FormatJSON = "json"
+)
+
+// format is how a changelog format is rendered and written.
+type format struct {
+	fileName string
+	// template is the built-in template of the format, none for JSON
+	template string
+	// headerRegex matches the headers of the version sections of the file and captures the version
+	headerRegex *regexp.Regexp
+}
+
+// asciidocHeaderRegex matches the headers of the version sections of an AsciiDoc changelog, like
+// `== 1.2.0 (2024-01-31)` or `== https://...[1.2.0] (2024-01-31)`, and captures the version.
+var asciidocHeaderRegex = regexp.MustCompile(`^={1,2} (?:\S+\[)?(Unreleased|v?[0-9]+\.[0-9]+\.[0-9]+[^\s\]]*)\]?(\s|$)`)
+
+var formats = map[string]format{
+	FormatMarkdown:       {fileName: changelogFileName, template: "template.tpl", headerRegex: versionHeaderRegex},
+	FormatKeepAChangelog: {fileName: changelogFileName, template: "keepachangelog.tpl", headerRegex: versionHeaderRegex},
+	FormatAsciiDoc:       {fileName: "CHANGELOG.adoc", template: "asciidoc.tpl", headerRegex: asciidocHeaderRegex},
+	FormatJSON:           {fileName: "CHANGELOG.json"},
+}
+
+// keepAChangelogSections are the Keep a Changelog names of the sections of the default and usual change types.
+var keepAChangelogSections = map[string]string{
+	"Features":     "Added",
+	"Fixes":        "Fixed",
+	"Refactors":    "Changed",
+	"Deprecations": "Deprecated",
+	"Removals":     "Removed",
+	"Security":     "Security",
+}
+
+// ValidateFormats checks every format is known and that no two formats write the same file.
+func ValidateFormats(names []string) error {
+	files := make(map[string]string)
+	for _, name := range names {
+		f, ok := formats[name]
+		if !ok {
+			return fmt.Errorf("invalid changelog format %q, supported values: %s, %s, %s, %s",
+				name, FormatMarkdown, FormatKeepAChangelog, FormatAsciiDoc, FormatJSON)
+		}
+		if other, ok := files[f.fileName]; ok {
+			return fmt.Errorf("changelog formats %s and %s both write %s", other, name, f.fileName)
+		}
+		files[f.fileName] = name
+	}
+	return nil
+}
+
+// GetFormatFilePath returns the path of the changelog file of a format of the project in dirPath.
+func GetFormatFilePath(dirPath string, format string) string {
+	return filepath.Join(dirPath, getFormat(format).fileName)
+}
+
+// getFormat returns a format by name, Markdown when the name is empty.
+func getFormat(name string) format {
+	if f, ok := formats[name]; ok {
+		return f
+	}
+	return formats[FormatMarkdown]
+}
+
+// jsonRelease is a release in a JSON changelog.
+type jsonRelease struct {
+	Version         string        `json:"version"`
+	PreviousVersion string        `json:"previous_version,omitempty"`
+	Tag             string        `json:"tag,omitempty"`
+	Date            string        `json:"date"`
+	CompareURL      string        `json:"compare_url,omitempty"`
+	BreakingChanges []jsonCommit  `json:"breaking_changes"`
+	Sections        []jsonSection `json:"sections"`
+}
+
+type jsonSection struct {
+	Title   string       `json:"title"`
+	Commits []jsonCommit `json:"commits"`
+}
+
+type jsonCommit struct {
+	Hash         string `json:"hash"`
+	ShortHash    string `json:"short_hash"`
+	URL          string `json:"url,omitempty"`
+	Type         string `json:"type"`
+	Scope        string `json:"scope,omitempty"`
+	Subject      string `json:"subject"`
+	Breaking     bool   `json:"breaking"`
+	BreakingNote string `json:"breaking_note,omitempty"`
+}
+
+// newJSONRelease returns the JSON release of the data of a section.
+func newJSONRelease(data data, links Links) jsonRelease {
+	toJSONCommits := func(commits []conventionalcommits.CommitData) []jsonCommit {
+		result := make([]jsonCommit, 0, len(commits))
+		for _, commit := range commits {
+			result = append(result, jsonCommit{
+				Hash:         commit.Hash,
+				ShortHash:    commit.ShortHash,
+				URL:          links.CommitURL(commit.Hash),
+				Type:         commit.ChangeType,
+				Scope:        commit.Scope,
+				Subject:      commit.Subject,
+				Breaking:     commit.Breaking,
+				BreakingNote: commit.BreakingDescription,
+			})
+		}
+		return result
+	}
+
+	release := jsonRelease{
+		Version:         data.Version,
+		PreviousVersion: data.PreviousVersion,
+		Tag:             data.Tag,
+		Date:            data.Date,
+		CompareURL:      data.CompareURL,
+		BreakingChanges: toJSONCommits(data.BreakingChanges),
+		Sections:        make([]jsonSection, 0, len(data.Sections)),
+	}
+	for _, section := range data.Sections {
+		release.Sections = append(release.Sections, jsonSection{Title: section.Title, Commits: toJSONCommits(section.Commits)})
+	}
+	return release
+}
+
+// marshalJSON returns the indented JSON of v, ending with a new line.
+func marshalJSON(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("fail to marshal json: %v", err)
+	}
+	return string(data) + "\n", nil
+}
+
+// upsertJSONFile writes release to the JSON changelog at filePath, in place of the release of the same version or
+// else first. Writing a release drops the Unreleased release.
+func upsertJSONFile(filePath string, release jsonRelease) error {
+	releases := make([]jsonRelease, 0)
+	existingContent, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read file: %v", err)
+	}
+	if len(strings.TrimSpace(string(existingContent))) > 0 {
+		if err := json.Unmarshal(existingContent, &releases); err != nil {
+			return fmt.Errorf("failed to read %s: %v", filePath, err)
+		}
+	}
+
+	replaced := false
+	kept := make([]jsonRelease, 0, len(releases))
+	for _, existing := range releases {
+		if existing.Version == Unreleased && release.Version != Unreleased {
+			continue
+		}
+		if existing.Version == release.Version && !replaced {
+			existing, replaced = release, true
+		}
+		kept = append(kept, existing)
+	}
+	releases = kept
+	if !replaced {
+		releases = append([]jsonRelease{release}, releases...)
+	}
+
+	content, err := marshalJSON(releases)
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.Write(filePath, []byte(content)); err != nil {
+		return fmt.Errorf("failed to write to file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package changelog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
+)
+
+func TestValidateFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		formats []string
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"several", []string{FormatMarkdown, FormatAsciiDoc, FormatJSON}, false},
+		{"unknown", []string{"html"}, true},
+		{"same file", []string{FormatMarkdown, FormatKeepAChangelog}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFormats(tt.formats); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRenderFormats(t *testing.T) {
+	commits := []conventionalcommits.CommitData{
+		{Hash: "aaaaaaa111", ShortHash: "aaaaaaa", CommonChangeType: "Features", ChangeType: "feat", Scope: "api", Subject: "add endpoint (#7)"},
+		{Hash: "bbbbbbb222", ShortHash: "bbbbbbb", CommonChangeType: "Fixes", ChangeType: "fix", Subject: "handle nil"},
+	}
+	opts := Options{
+		Date:        time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		Tag:         "v1.1.0",
+		PreviousTag: "v1.0.0",
+		Links:       ResolveLinks("git@github.com:owner/repo.git", Links{}),
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: FormatKeepAChangelog,
+			expected: "## [1.1.0] - 2024-02-01\n\n" +
+				"### Added\n- **api**: add endpoint ([#7](https://github.com/owner/repo/issues/7)) ([aaaaaaa](https://github.com/owner/repo/commit/aaaaaaa111))\n\n" +
+				"### Fixed\n- handle nil ([bbbbbbb](https://github.com/owner/repo/commit/bbbbbbb222))\n\n" +
+				"[1.1.0]: https://github.com/owner/repo/compare/v1.0.0...v1.1.0\n\n",
+		},
+		{
+			format: FormatAsciiDoc,
+			expected: "== https://github.com/owner/repo/compare/v1.0.0...v1.1.0[1.1.0] (2024-02-01)\n\n" +
+				"=== Features\n\n* *api*: add endpoint (https://github.com/owner/repo/issues/7[#7]) (https://github.com/owner/repo/commit/aaaaaaa111[aaaaaaa])\n\n" +
+				"=== Fixes\n\n* handle nil (https://github.com/owner/repo/commit/bbbbbbb222[bbbbbbb])\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			opts.Format = tt.format
+			section, err := Render("1.1.0", commits, conventionalcommits.DefaultChangeTypes(), opts)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if section != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, section)
+			}
+		})
+	}
+}
+
+func TestApplyJSON(t *testing.T) {
+	dirPath := t.TempDir()
+	changeTypes := conventionalcommits.DefaultChangeTypes()
+	opts := Options{Format: FormatJSON, Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Tag: "v1.0.0"}
+
+	first := []conventionalcommits.CommitData{
+		{Hash: "aaaaaaa111", ShortHash: "aaaaaaa", CommonChangeType: "Features", ChangeType: "feat", Subject: "first"},
+	}
+	second := []conventionalcommits.CommitData{
+		{
+			Hash: "bbbbbbb222", ShortHash: "bbbbbbb", CommonChangeType: "Features", ChangeType: "feat", Scope: "api",
+			Subject: "drop v1", Breaking: true, BreakingDescription: "v1 is gone",
+		},
+	}
+
+	if _, err := Apply(dirPath, "1.0.0", first, changeTypes, opts); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	opts.Tag = "v2.0.0"
+	if _, err := Apply(dirPath, Unreleased, first, changeTypes, opts); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	// The release of the same version is replaced
+	changelogFilePath, err := Apply(dirPath, Unreleased, second, changeTypes, opts)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if changelogFilePath != filepath.Join(dirPath, "CHANGELOG.json") {
+		t.Errorf("expected CHANGELOG.json, got %s", changelogFilePath)
+	}
+
+	content, err := os.ReadFile(changelogFilePath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var releases []jsonRelease
+	if err := json.Unmarshal(content, &releases); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if len(releases) != 2 || releases[0].Version != Unreleased || releases[1].Version != "1.0.0" {
+		t.Fatalf("expected the Unreleased and 1.0.0 releases, got %+v", releases)
+	}
+	if releases[1].Date != "2024-02-01" || releases[1].Tag != "v1.0.0" {
+		t.Errorf("expected the date and tag of 1.0.0, got %+v", releases[1])
+	}
+	unreleased := releases[0]
+	if len(unreleased.BreakingChanges) != 1 || unreleased.BreakingChanges[0].BreakingNote != "v1 is gone" {
+		t.Errorf("expected the breaking change, got %+v", unreleased.BreakingChanges)
+	}
+	if len(unreleased.Sections) != 1 || unreleased.Sections[0].Title != "Features" {
+		t.Fatalf("expected a Features section, got %+v", unreleased.Sections)
+	}
+	commit := unreleased.Sections[0].Commits[0]
+	if commit.Hash != "bbbbbbb222" || commit.Scope != "api" || commit.Subject != "drop v1" || !commit.Breaking {
+		t.Errorf("expected the commit of the second apply, got %+v", commit)
+	}
+
+	// The release takes the place of the Unreleased release
+	opts.Tag = "v2.0.0"
+	if _, err := Apply(dirPath, "2.0.0", second, changeTypes, opts); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	content, _ = os.ReadFile(changelogFilePath)
+	releases = nil
+	if err := json.Unmarshal(content, &releases); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(releases) != 2 || releases[0].Version != "2.0.0" || releases[1].Version != "1.0.0" {
+		t.Errorf("expected the 2.0.0 and 1.0.0 releases, got %+v", releases)
+	}
+}
@@ -0,0 +1,27 @@
+{{- define "hash" }}{{ with commitURL .Hash }}[{{ $.ShortHash }}]({{ . }}){{ else }}#{{ .ShortHash }}{{ end }}{{ end -}}
+
+## [{{ .Version }}]{{ if ne .Version "Unreleased" }} - {{ .Date }}{{ end }}
+{{- printf "\n" -}}
+
+{{- if .BreakingChanges }}
+### Breaking changes
+{{- range .BreakingChanges }}
+- {{ if .Scope }}**{{ .Scope }}**: {{ end }}{{ linkReferences .BreakingDescription }} ({{ template "hash" . }})
+{{- end }}
+{{- printf "\n" -}}
+{{- end }}
+
+{{- range .Sections }}
+### {{ keepAChangelogSection .Title }}
+{{- $showType := .ShowType }}
+{{- range .Commits }}
+- {{ if $showType }}{{ .ChangeType }}{{ if .Scope }}(**{{ .Scope }}**): {{ else }}: {{ end }}{{ else if .Scope }}**{{ .Scope }}**: {{ end }}{{ linkReferences .Subject }} ({{ template "hash" . }})
+{{- end }}
+{{- printf "\n" -}}
+{{- end }}
+
+{{- with .CompareURL }}
+[{{ $.Version }}]: {{ . }}
+{{- printf "\n" -}}
+{{- end }}
+{{- printf "\n" -}}
\ No newline at end of file
@@ -0,0 +1,175 @@
+package changelog
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+const (
+	ForgeGitHub    = "github"
+	ForgeGitLab    = "gitlab"
+	ForgeBitbucket = "bitbucket"
+	ForgeGitea     = "gitea"
+)
+
+// Links are the web pages of a repository a changelog links to. In the patterns, {url} stands for the web page of the
+// repository, {hash} for a commit hash, {from} and {to} for the tags compared and {number} for an issue or merge
+// request number. Links without pattern are not rendered.
+type Links struct {
+	// Forge gives the default patterns: github, gitlab, bitbucket or gitea. It is detected from the host of the
+	// repository when not set.
+	Forge string `json:"forge,omitempty" yaml:"forge,omitempty"`
+	// URL is the web page of the repository, taken from the origin remote when not set
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	Commit       string `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Compare      string `json:"compare,omitempty" yaml:"compare,omitempty"`
+	Issue        string `json:"issue,omitempty" yaml:"issue,omitempty"`
+	MergeRequest string `json:"merge_request,omitempty" yaml:"merge_request,omitempty"`
+}
+
+// forgeLinks are the patterns of each forge. Merge requests are the `!45` references of GitLab, the pull requests of
+// the other forges share the numbers of their issues.
+var forgeLinks = map[string]Links{
+	ForgeGitHub: {
+		Commit:  "{url}/commit/{hash}",
+		Compare: "{url}/compare/{from}...{to}",
+		Issue:   "{url}/issues/{number}",
+	},
+	ForgeGitLab: {
+		Commit:       "{url}/-/commit/{hash}",
+		Compare:      "{url}/-/compare/{from}...{to}",
+		Issue:        "{url}/-/issues/{number}",
+		MergeRequest: "{url}/-/merge_requests/{number}",
+	},
+	ForgeBitbucket: {
+		Commit:  "{url}/commits/{hash}",
+		Compare: "{url}/branches/compare/{to}%0D{from}",
+		Issue:   "{url}/issues/{number}",
+	},
+	ForgeGitea: {
+		Commit:  "{url}/commit/{hash}",
+		Compare: "{url}/compare/{from}...{to}",
+		Issue:   "{url}/issues/{number}",
+	},
+}
+
+// referenceRegex matches the `#123` issue and `!45` merge request references of a commit subject.
+var referenceRegex = regexp.MustCompile(`(^|[\s(\[,])([#!])([0-9]+)\b`)
+
+// Validate checks the forge is a known one.
+func (l Links) Validate() error {
+	if _, ok := forgeLinks[l.Forge]; len(l.Forge) > 0 && !ok {
+		return fmt.Errorf("invalid forge %q, supported values: %s, %s, %s, %s", l.Forge, ForgeGitHub, ForgeGitLab, ForgeBitbucket, ForgeGitea)
+	}
+	return nil
+}
+
+// ResolveLinks returns the links of a repository from the URL of its origin remote, empty when it has none. The forge
+// defaults are used for the patterns links does not set, and its URL for the web page of the repository when set.
+func ResolveLinks(remoteURL string, links Links) Links {
+	if len(links.URL) == 0 {
+		links.URL = git.WebURL(remoteURL)
+	}
+	links.URL = strings.TrimSuffix(links.URL, "/")
+	if len(links.URL) == 0 {
+		return Links{}
+	}
+
+	if len(links.Forge) == 0 {
+		links.Forge = detectForge(links.URL)
+	}
+	defaults := forgeLinks[links.Forge]
+	if len(links.Commit) == 0 {
+		links.Commit = defaults.Commit
+	}
+	if len(links.Compare) == 0 {
+		links.Compare = defaults.Compare
+	}
+	if len(links.Issue) == 0 {
+		links.Issue = defaults.Issue
+	}
+	if len(links.MergeRequest) == 0 {
+		links.MergeRequest = defaults.MergeRequest
+	}
+	return links
+}
+
+// detectForge tells the forge of a repository from the host of its web page, empty when it is not a known one.
+func detectForge(webURL string) string {
+	u, err := url.Parse(webURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "github.com" || strings.HasPrefix(host, "github."):
+		return ForgeGitHub
+	case strings.Contains(host, "gitlab"):
+		return ForgeGitLab
+	case host == "bitbucket.org" || strings.HasPrefix(host, "bitbucket."):
+		return ForgeBitbucket
+	case host == "codeberg.org" || strings.Contains(host, "gitea"):
+		return ForgeGitea
+	}
+	return ""
+}
+
+// CommitURL returns the page of a commit, empty without commit pattern.
+func (l Links) CommitURL(hash string) string {
+	return l.expand(l.Commit, "{hash}", hash)
+}
+
+// CompareURL returns the page comparing two tags, HEAD when to is empty. It is empty without compare pattern or
+// without from.
+func (l Links) CompareURL(from string, to string) string {
+	if len(from) == 0 {
+		return ""
+	}
+	if len(to) == 0 {
+		to = "HEAD"
+	}
+	return l.expand(l.Compare, "{from}", url.PathEscape(from), "{to}", url.PathEscape(to))
+}
+
+// LinkReferences turns the `#123` and `!45` references of a text into Markdown links, leaving those without pattern
+// as they are.
+func (l Links) LinkReferences(text string) string {
+	return l.linkReferences(text, func(reference string, link string) string {
+		return fmt.Sprintf("[%s](%s)", reference, link)
+	})
+}
+
+// LinkReferencesAsciiDoc turns the `#123` and `!45` references of a text into AsciiDoc links, leaving those without
+// pattern as they are.
+func (l Links) LinkReferencesAsciiDoc(text string) string {
+	return l.linkReferences(text, func(reference string, link string) string {
+		return fmt.Sprintf("%s[%s]", link, reference)
+	})
+}
+
+func (l Links) linkReferences(text string, render func(reference string, link string) string) string {
+	return referenceRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := referenceRegex.FindStringSubmatch(match)
+		pattern := l.Issue
+		if parts[2] == "!" {
+			pattern = l.MergeRequest
+		}
+		link := l.expand(pattern, "{number}", parts[3])
+		if len(link) == 0 {
+			return match
+		}
+		return parts[1] + render(parts[2]+parts[3], link)
+	})
+}
+
+func (l Links) expand(pattern string, oldnew ...string) string {
+	if len(pattern) == 0 || len(l.URL) == 0 {
+		return ""
+	}
+	return strings.NewReplacer(append([]string{"{url}", l.URL}, oldnew...)...).Replace(pattern)
+}
@@ -0,0 +1,102 @@
+package changelog
+
+import "testing"
+
+func TestResolveLinks(t *testing.T) {
+	tests := []struct {
+		name      string
+		remoteURL string
+		links     Links
+		commit    string
+		compare   string
+	}{
+		{
+			name:      "github ssh",
+			remoteURL: "git@github.com:owner/repo.git",
+			commit:    "https://github.com/owner/repo/commit/abc",
+			compare:   "https://github.com/owner/repo/compare/1.0.0...1.1.0",
+		},
+		{
+			name:      "gitlab https",
+			remoteURL: "https://gitlab.com/group/sub/repo.git",
+			commit:    "https://gitlab.com/group/sub/repo/-/commit/abc",
+			compare:   "https://gitlab.com/group/sub/repo/-/compare/1.0.0...1.1.0",
+		},
+		{
+			name:      "bitbucket",
+			remoteURL: "git@bitbucket.org:team/repo.git",
+			commit:    "https://bitbucket.org/team/repo/commits/abc",
+			compare:   "https://bitbucket.org/team/repo/branches/compare/1.1.0%0D1.0.0",
+		},
+		{
+			name:      "gitea",
+			remoteURL: "https://codeberg.org/owner/repo",
+			commit:    "https://codeberg.org/owner/repo/commit/abc",
+			compare:   "https://codeberg.org/owner/repo/compare/1.0.0...1.1.0",
+		},
+		{
+			name:      "self-hosted forge",
+			remoteURL: "ssh://git@git.example.com:2222/team/repo.git",
+			links:     Links{Forge: ForgeGitLab, Compare: "{url}/compare?from={from}&to={to}"},
+			commit:    "https://git.example.com/team/repo/-/commit/abc",
+			compare:   "https://git.example.com/team/repo/compare?from=1.0.0&to=1.1.0",
+		},
+		{
+			name:      "unknown forge",
+			remoteURL: "https://git.example.com/team/repo.git",
+		},
+		{
+			name:    "url without remote",
+			links:   Links{URL: "https://github.com/owner/repo/"},
+			commit:  "https://github.com/owner/repo/commit/abc",
+			compare: "https://github.com/owner/repo/compare/1.0.0...1.1.0",
+		},
+		{
+			name:      "local remote",
+			remoteURL: "/srv/git/repo.git",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := ResolveLinks(tt.remoteURL, tt.links)
+			if commit := links.CommitURL("abc"); commit != tt.commit {
+				t.Errorf("expected commit link %q, got %q", tt.commit, commit)
+			}
+			if compare := links.CompareURL("1.0.0", "1.1.0"); compare != tt.compare {
+				t.Errorf("expected compare link %q, got %q", tt.compare, compare)
+			}
+		})
+	}
+}
+
+func TestLinkReferences(t *testing.T) {
+	gitlab := ResolveLinks("git@gitlab.com:group/repo.git", Links{})
+	github := ResolveLinks("git@github.com:owner/repo.git", Links{})
+
+	tests := []struct {
+		links    Links
+		text     string
+		expected string
+	}{
+		{gitlab, "fix login (#12, !45)", "fix login ([#12](https://gitlab.com/group/repo/-/issues/12), [!45](https://gitlab.com/group/repo/-/merge_requests/45))"},
+		{github, "#3 closes it, not !4 nor a#5", "[#3](https://github.com/owner/repo/issues/3) closes it, not !4 nor a#5"},
+		{Links{}, "fix #12", "fix #12"},
+	}
+
+	for _, tt := range tests {
+		if result := tt.links.LinkReferences(tt.text); result != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, result)
+		}
+	}
+}
+
+func TestCompareURLUnreleased(t *testing.T) {
+	links := ResolveLinks("git@github.com:owner/repo.git", Links{})
+	if compare := links.CompareURL("app/v1.0.0", ""); compare != "https://github.com/owner/repo/compare/app%2Fv1.0.0...HEAD" {
+		t.Errorf("expected a compare link up to HEAD, got %q", compare)
+	}
+	if compare := links.CompareURL("", "1.0.0"); compare != "" {
+		t.Errorf("expected no compare link for a first release, got %q", compare)
+	}
+}
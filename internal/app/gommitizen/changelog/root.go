@@ -0,0 +1,86 @@
+package changelog
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
+)
+
+// ProjectRelease is the release of a project in a bump, as the root changelog lists it.
+type ProjectRelease struct {
+	Release
+	// Name is the alias of the project and Path its directory relative to the repository root
+	Name        string
+	Path        string
+	ChangeTypes conventionalcommits.ChangeTypes
+}
+
+// rootData is what the root changelog template renders for a bump.
+type rootData struct {
+	Date    string
+	Time    time.Time
+	RepoURL string
+
+	Projects []projectData
+}
+
+// projectData is the section of a project in the root changelog: its name and path next to the data of its release.
+type projectData struct {
+	Name string
+	Path string
+	data
+}
+
+// RenderRoot returns the section of a bump in the root changelog of the repository: the release of every project
+// bumped, under the date of the bump.
+func RenderRoot(date time.Time, projects []ProjectRelease, links Links) (string, error) {
+	rootData := rootData{
+		Date:    date.Format("2006-01-02"),
+		Time:    date,
+		RepoURL: links.URL,
+	}
+	for _, project := range projects {
+		opts := Options{
+			Date:            date,
+			PreviousVersion: project.PreviousVersion,
+			Tag:             project.Tag,
+			PreviousTag:     project.PreviousTag,
+			Links:           links,
+		}
+		rootData.Projects = append(rootData.Projects, projectData{
+			Name: project.Name,
+			Path: project.Path,
+			data: newData(project.Version, project.Commits, project.ChangeTypes, opts),
+		})
+	}
+
+	tpl, err := loadTemplate("root.tpl", "", links)
+	if err != nil {
+		return "", fmt.Errorf("fail to load template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = tpl.Execute(&buf, rootData)
+	if err != nil {
+		return "", fmt.Errorf("fail to execute template: %v", err)
+	}
+
+	return buf.String(), nil
+}
+
+// ApplyRoot adds the section of a bump to the top of the root changelog at filePath.
+func ApplyRoot(filePath string, date time.Time, projects []ProjectRelease, links Links) error {
+	section, err := RenderRoot(date, projects, links)
+	if err != nil {
+		return err
+	}
+
+	err = prependToFile(filePath, *bytes.NewBufferString(section))
+	if err != nil {
+		return fmt.Errorf("fail to prepend to file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+{{- define "hash" }}{{ with commitURL .Hash }}[{{ $.ShortHash }}]({{ . }}){{ else }}#{{ .ShortHash }}{{ end }}{{ end -}}
+
+# {{ .Date }}
+{{- printf "\n" -}}
+
+{{- range .Projects }}
+## {{ .Name }} {{ with .PreviousVersion }}{{ . }} -> {{ end }}{{ if .CompareURL }}[{{ .Version }}]({{ .CompareURL }}){{ else }}{{ .Version }}{{ end }}
+{{- printf "\n" -}}
+
+{{- if .BreakingChanges }}
+### Breaking changes
+{{- range .BreakingChanges }}
+- {{ if .Scope }}**{{ .Scope }}**: {{ end }}{{ linkReferences .BreakingDescription }} ({{ template "hash" . }})
+{{- end }}
+{{- printf "\n" -}}
+{{- end }}
+
+{{- range .Sections }}
+### {{ .Title }}
+{{- $showType := .ShowType }}
+{{- range .Commits }}
+- {{ if $showType }}{{ .ChangeType }}{{ if .Scope }}(**{{ .Scope }}**): {{ else }}: {{ end }}{{ else if .Scope }}**{{ .Scope }}**: {{ end }}{{ linkReferences .Subject }} ({{ template "hash" . }})
+{{- end }}
+{{- printf "\n" -}}
+{{- end }}
+{{- end }}
\ No newline at end of file
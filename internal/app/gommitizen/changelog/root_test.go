@@ -0,0 +1,77 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
+)
+
+func TestRenderRoot(t *testing.T) {
+	date := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	projects := []ProjectRelease{
+		{
+			Release: Release{
+				Version:         "1.3.0",
+				PreviousVersion: "1.2.0",
+				Tag:             "api/v1.3.0",
+				PreviousTag:     "api/v1.2.0",
+				Commits: []conventionalcommits.CommitData{
+					{Hash: "aaaaaaa111", ShortHash: "aaaaaaa", CommonChangeType: "Features", ChangeType: "feat", Subject: "add endpoint",
+						Breaking: true, BreakingDescription: "drop v1"},
+				},
+			},
+			Name:        "api",
+			Path:        "services/api",
+			ChangeTypes: conventionalcommits.DefaultChangeTypes(),
+		},
+		{
+			Release: Release{
+				Version: "0.1.0",
+				Tag:     "web/v0.1.0",
+				Commits: []conventionalcommits.CommitData{
+					{Hash: "bbbbbbb222", ShortHash: "bbbbbbb", CommonChangeType: "Fixes", ChangeType: "fix", Scope: "ui", Subject: "align (#3)"},
+				},
+			},
+			Name:        "web",
+			Path:        "web",
+			ChangeTypes: conventionalcommits.DefaultChangeTypes(),
+		},
+	}
+
+	section, err := RenderRoot(date, projects, Links{})
+	if err != nil {
+		t.Fatalf("RenderRoot() error = %v", err)
+	}
+	expected := "# 2024-02-01\n\n" +
+		"## api 1.2.0 -> 1.3.0\n\n### Breaking changes\n- drop v1 (#aaaaaaa)\n\n### Features\n- add endpoint (#aaaaaaa)\n\n" +
+		"## web 0.1.0\n\n### Fixes\n- **ui**: align (#3) (#bbbbbbb)\n"
+	if section != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, section)
+	}
+
+	links := ResolveLinks("git@github.com:owner/repo.git", Links{})
+	section, err = RenderRoot(date, projects[:1], links)
+	if err != nil {
+		t.Fatalf("RenderRoot() error = %v", err)
+	}
+	heading := "## api 1.2.0 -> [1.3.0](https://github.com/owner/repo/compare/api%2Fv1.2.0...api%2Fv1.3.0)\n"
+	if !strings.Contains(section, heading) {
+		t.Errorf("expected heading %q, got:\n%s", heading, section)
+	}
+
+	filePath := filepath.Join(t.TempDir(), "RELEASES.md")
+	if err := os.WriteFile(filePath, []byte("# 2024-01-01\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := ApplyRoot(filePath, date, projects[1:], Links{}); err != nil {
+		t.Fatalf("ApplyRoot() error = %v", err)
+	}
+	content, _ := os.ReadFile(filePath)
+	if string(content) != "# 2024-02-01\n\n## web 0.1.0\n\n### Fixes\n- **ui**: align (#3) (#bbbbbbb)\n# 2024-01-01\n" {
+		t.Errorf("expected the bump on top of the root changelog, got:\n%s", content)
+	}
+}
@@ -1,42 +1,21 @@
-# {{ .Version }} ({{ .Date }})
+{{- define "hash" }}{{ with commitURL .Hash }}[{{ $.ShortHash }}]({{ . }}){{ else }}#{{ .ShortHash }}{{ end }}{{ end -}}
+
+# {{ if .CompareURL }}[{{ .Version }}]({{ .CompareURL }}){{ else }}{{ .Version }}{{ end }} ({{ .Date }})
 {{- printf "\n" -}}
 
 {{- if .BreakingChanges }}
 ## Breaking changes
 {{- range .BreakingChanges }}
-- {{ if .Scope }}**{{ .Scope }}**: {{ end }}{{ .Subject }} (#{{ .ShortHash }})
-{{- end }}
-{{- printf "\n" -}}
-{{- end }}
-
-{{- if .Features }}
-## Features
-{{- range .Features }}
-- {{ if .Scope }}**{{ .Scope }}**: {{ end }}{{ .Subject }} (#{{ .ShortHash }})
-{{- end }}
-{{- printf "\n" -}}
-{{- end }}
-
-{{- if .BugFixes }}
-## Fixes
-{{- range .BugFixes }}
-- {{ if .Scope }}**{{ .Scope }}**: {{ end }}{{ .Subject }} (#{{ .ShortHash }})
+- {{ if .Scope }}**{{ .Scope }}**: {{ end }}{{ linkReferences .BreakingDescription }} ({{ template "hash" . }})
 {{- end }}
 {{- printf "\n" -}}
 {{- end }}
 
-{{- if .Refactors }}
-## Refactors
-{{- range .Refactors }}
-- {{ if .Scope }}**{{ .Scope }}**: {{ end }}{{ .Subject }} (#{{ .ShortHash }})
+{{- range .Sections }}
+## {{ .Title }}
+{{- $showType := .ShowType }}
+{{- range .Commits }}
+- {{ if $showType }}{{ .ChangeType }}{{ if .Scope }}(**{{ .Scope }}**): {{ else }}: {{ end }}{{ else if .Scope }}**{{ .Scope }}**: {{ end }}{{ linkReferences .Subject }} ({{ template "hash" . }})
 {{- end }}
 {{- printf "\n" -}}
-{{- end }}
-
-{{- if .Miscellaneous }}
-## Miscellaneous
-{{- range .Miscellaneous }}
-- {{ .ChangeType }}{{ if .Scope }}(**{{ .Scope }}**): {{ else }}: {{ end }}{{ .Subject }} (#{{ .ShortHash }})
-{{- end }}
-{{- printf "\n" -}}
-{{- end }}
+{{- end }}
\ No newline at end of file
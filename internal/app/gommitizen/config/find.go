@@ -1,63 +1,169 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
 )
 
 const (
 	defaultFileName = ".version.json"
 )
 
-func FindConfigVersionFilePath(path string) ([]string, error) {
-	var list []string
+// skippedDirNames are never searched for projects, whether discovery asks git for the files or walks the tree.
+var skippedDirNames = map[string]bool{".git": true, "node_modules": true, "vendor": true}
+
+// FindConfigVersionFilePath returns the config version files under path, sorted by path. Inside a git repository the
+// files ignored by git are left out. The include, exclude and max_depth discovery settings of the repository config
+// are applied.
+func FindConfigVersionFilePath(repo git.Repository, path string) ([]string, error) {
+	repositoryConfig, err := FindRepositoryConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return discover(repo, path, repositoryConfig.Discovery)
+}
+
+// FindConfigVersions reads the config versions of the projects under path found by FindConfigVersionFilePath, or only
+// of the projects with the given alias when it is not empty. Each config version knows the discovered projects nested
+// in it. Config files that cannot be read are reported and skipped.
+func FindConfigVersions(repo git.Repository, path string, alias string) ([]*ConfigVersion, error) {
+	filePaths, err := FindConfigVersionFilePath(repo, path)
+	if err != nil {
+		return nil, err
+	}
 
-	err := filepath.Walk(path, func(subpath string, info os.FileInfo, err error) error {
+	configVersions := make([]*ConfigVersion, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		configVersion, err := ReadConfigVersion(filePath)
 		if err != nil {
-			return err
+			slog.Error(fmt.Sprintf("reading config version: %v", err))
+			continue
 		}
-		if !info.IsDir() && info.Name() == defaultFileName {
-			list = append(list, subpath)
+		if len(alias) > 0 && configVersion.Alias != alias {
+			continue
 		}
-		return nil
-	})
-
-	return list, err
+		configVersion.nestedProjects = nestedProjectDirs(configVersion.dirPath, filePaths)
+		configVersions = append(configVersions, configVersion)
+	}
+	return configVersions, nil
 }
 
-func FindConfigVersionFilePathByAlias(path, alias string) ([]string, error) {
+func discover(repo git.Repository, path string, discovery Discovery) ([]string, error) {
+	relPaths, err := listConfigVersionFiles(repo, path)
+	if err != nil {
+		return nil, err
+	}
+
 	var list []string
+	for _, relPath := range relPaths {
+		projectDir := filepath.ToSlash(filepath.Dir(relPath))
+		if !discovery.matches(projectDir) {
+			slog.Debug(fmt.Sprintf("discovery: skipping %s", relPath))
+			continue
+		}
+		list = append(list, filepath.Join(path, relPath))
+	}
+
+	sort.Strings(list)
+	return list, nil
+}
+
+// listConfigVersionFiles returns the config version files under path, relative to it. It asks git when path is in a
+// repository, and walks the tree otherwise.
+func listConfigVersionFiles(repo git.Repository, path string) ([]string, error) {
+	files, err := repo.ListFiles(":(glob)**/" + defaultFileName)
+	if err == nil {
+		existing := make([]string, 0, len(files))
+		for _, file := range files {
+			if inSkippedDir(file) {
+				continue
+			}
+			// Deleted files are still listed until the deletion is committed
+			if _, err := os.Stat(filepath.Join(path, file)); err == nil {
+				existing = append(existing, filepath.FromSlash(file))
+			}
+		}
+		return existing, nil
+	}
+	slog.Debug(fmt.Sprintf("discovery: walking %s, git ls-files failed: %v", path, err))
 
-	err := filepath.Walk(path, func(subpath string, info os.FileInfo, err error) error {
+	var list []string
+	err = filepath.WalkDir(path, func(subpath string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return fmt.Errorf("error walking subpath: %v", err)
 		}
-		if !info.IsDir() && info.Name() == defaultFileName {
-			file, err := os.Open(subpath)
+		if entry.IsDir() && subpath != path && skippedDirNames[entry.Name()] {
+			return filepath.SkipDir
+		}
+		if !entry.IsDir() && entry.Name() == defaultFileName {
+			relPath, err := filepath.Rel(path, subpath)
 			if err != nil {
-				return fmt.Errorf("error opening file: %v", err)
-			}
-			defer func(file *os.File) {
-				err := file.Close()
-				if err != nil {
-					slog.Error(fmt.Sprintf("error closing file: %v", err))
-				}
-			}(file)
-
-			var data map[string]interface{}
-			if err := json.NewDecoder(file).Decode(&data); err != nil {
-				return fmt.Errorf("error decoding file: %v", err)
-			}
-
-			if dataAlias, ok := data["alias"].(string); ok && dataAlias == alias {
-				list = append(list, subpath)
+				return err
 			}
+			list = append(list, relPath)
 		}
 		return nil
 	})
 
 	return list, err
 }
+
+// inSkippedDir tells whether a slash separated file path is inside one of skippedDirNames.
+func inSkippedDir(file string) bool {
+	dirParts := strings.Split(filepath.ToSlash(file), "/")
+	for _, dirName := range dirParts[:len(dirParts)-1] {
+		if skippedDirNames[dirName] {
+			return true
+		}
+	}
+	return false
+}
+
+// matches tells whether the project in projectDir, a slash separated path relative to the discovery root, is
+// discovered. Globs match the directory or any of its parents, and "**" matches any number of directories.
+func (d Discovery) matches(projectDir string) bool {
+	depth := 0
+	if projectDir != "." {
+		depth = strings.Count(projectDir, "/") + 1
+	}
+	if d.MaxDepth > 0 && depth > d.MaxDepth {
+		return false
+	}
+
+	for _, pattern := range d.Exclude {
+		if matchDirGlob(pattern, projectDir) {
+			return false
+		}
+	}
+	if len(d.Include) == 0 {
+		return true
+	}
+	for _, pattern := range d.Include {
+		if matchDirGlob(pattern, projectDir) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchDirGlob(pattern string, dir string) bool {
+	pattern = filepath.ToSlash(pattern)
+	if dir == "." {
+		return pattern == "." || git.MatchGlob(pattern, "")
+	}
+
+	dirParts := strings.Split(dir, "/")
+	for i := 1; i <= len(dirParts); i++ {
+		if git.MatchGlob(pattern, strings.Join(dirParts[:i], "/")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+func newDiscoveryRepository(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", root).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, output)
+	}
+
+	writeTestFile(t, filepath.Join(root, ".gitignore"), "node_modules/\n")
+	for _, project := range []string{"zeta", "alpha", "services/api", "services/web/app", "node_modules/lib"} {
+		writeTestFile(t, filepath.Join(root, project, defaultFileName), `{"alias": "`+filepath.Base(project)+`"}`)
+	}
+	writeTestFile(t, filepath.Join(root, defaultFileName), `{"alias": "root"}`)
+	return root
+}
+
+func TestFindConfigVersionFilePathRespectsGitignore(t *testing.T) {
+	root := newDiscoveryRepository(t)
+
+	paths, err := FindConfigVersionFilePath(git.NewRepository(root), root)
+	if err != nil {
+		t.Fatalf("FindConfigVersionFilePath() error = %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(root, defaultFileName),
+		filepath.Join(root, "alpha", defaultFileName),
+		filepath.Join(root, "services", "api", defaultFileName),
+		filepath.Join(root, "services", "web", "app", defaultFileName),
+		filepath.Join(root, "zeta", defaultFileName),
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestFindConfigVersionFilePathDiscoverySettings(t *testing.T) {
+	root := newDiscoveryRepository(t)
+	writeTestFile(t, filepath.Join(root, ".gommitizen.yaml"), `discovery:
+  include:
+    - services/**
+    - zeta
+  exclude:
+    - "**/web"
+  max_depth: 2
+`)
+
+	paths, err := FindConfigVersionFilePath(git.NewRepository(root), root)
+	if err != nil {
+		t.Fatalf("FindConfigVersionFilePath() error = %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(root, "services", "api", defaultFileName),
+		filepath.Join(root, "zeta", defaultFileName),
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestFindConfigVersionsByAlias(t *testing.T) {
+	root := newDiscoveryRepository(t)
+
+	configVersions, err := FindConfigVersions(git.NewRepository(root), root, "api")
+	if err != nil {
+		t.Fatalf("FindConfigVersions() error = %v", err)
+	}
+	if len(configVersions) != 1 || configVersions[0].GetDirPath() != filepath.Join(root, "services", "api") {
+		t.Errorf("expected only the api project, got %v", configVersions)
+	}
+
+	configVersions, err = FindConfigVersions(git.NewRepository(root), root, "lib")
+	if err != nil {
+		t.Fatalf("FindConfigVersions() error = %v", err)
+	}
+	if len(configVersions) != 0 {
+		t.Errorf("expected ignored project not to be found, got %v", configVersions)
+	}
+}
+
+func TestFindConfigVersionsNestedProjects(t *testing.T) {
+	root := t.TempDir()
+	repo := git.NewFakeRepository()
+	repo.WorkDir = root
+	for _, project := range []string{".", "client", "client/gen", "plugins/auth"} {
+		filePath := filepath.Join(root, project, defaultFileName)
+		writeTestFile(t, filePath, `{"version": "0.1.0", "alias": "`+filepath.Base(project)+`"}`)
+		repo.AddCommit("chore: add "+project, filePath)
+	}
+
+	configVersions, err := FindConfigVersions(repo, root, "")
+	if err != nil {
+		t.Fatalf("FindConfigVersions() error = %v", err)
+	}
+	if len(configVersions) != 4 {
+		t.Fatalf("expected 4 projects, got %d", len(configVersions))
+	}
+
+	expected := []string{"client", filepath.Join("plugins", "auth")}
+	if nested := configVersions[0].GetNestedProjects(); !reflect.DeepEqual(nested, expected) {
+		t.Errorf("expected %v nested in the root project, got %v", expected, nested)
+	}
+	if nested := configVersions[1].GetNestedProjects(); !reflect.DeepEqual(nested, []string{"gen"}) {
+		t.Errorf("expected [gen] nested in client, got %v", nested)
+	}
+}
+
+func TestListConfigVersionFilesSkipsDirs(t *testing.T) {
+	root := t.TempDir()
+	repo := git.NewFakeRepository()
+	for _, project := range []string{"api", "vendor/lib", "web/node_modules/pkg"} {
+		filePath := filepath.Join(project, defaultFileName)
+		writeTestFile(t, filepath.Join(root, filePath), "{}")
+		repo.AddCommit("chore: add "+project, filePath)
+	}
+
+	files, err := listConfigVersionFiles(repo, root)
+	if err != nil {
+		t.Fatalf("listConfigVersionFiles() error = %v", err)
+	}
+	expected := []string{filepath.Join("api", defaultFileName)}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+}
+
+func TestMatchDirGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		dir      string
+		expected bool
+	}{
+		{"vendor", "vendor", true},
+		{"vendor", "vendor/lib", true},
+		{"vendor", "src/vendor", false},
+		{"**/vendor", "src/vendor/lib", true},
+		{"services/*", "services/api", true},
+		{"services/*", "services", false},
+		{"services/**", "services", true},
+		{"**", ".", true},
+		{"api", ".", false},
+		{".", ".", true},
+		{".", "api", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchDirGlob(tt.pattern, tt.dir); got != tt.expected {
+			t.Errorf("matchDirGlob(%q, %q): expected %t, got %t", tt.pattern, tt.dir, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hook is a hook command together with the name of the hook point it is defined for.
+type Hook struct {
+	Name    string
+	Command string
+}
+
+// HookCommands are the commands of a hook point, run in order until one fails. A single command can be written as a
+// string instead of a list.
+type HookCommands []string
+
+func (h *HookCommands) UnmarshalJSON(data []byte) error {
+	var command string
+	if err := json.Unmarshal(data, &command); err == nil {
+		*h = HookCommands{command}
+		return nil
+	}
+	var commands []string
+	if err := json.Unmarshal(data, &commands); err != nil {
+		return fmt.Errorf("hook: expected a command or a list of commands")
+	}
+	*h = commands
+	return nil
+}
+
+func (h HookCommands) MarshalJSON() ([]byte, error) {
+	if len(h) == 1 {
+		return json.Marshal(h[0])
+	}
+	return json.Marshal([]string(h))
+}
+
+func (h *HookCommands) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*h = HookCommands{value.Value}
+		return nil
+	}
+	var commands []string
+	if err := value.Decode(&commands); err != nil {
+		return fmt.Errorf("hook: expected a command or a list of commands")
+	}
+	*h = commands
+	return nil
+}
+
+func (h HookCommands) MarshalYAML() (interface{}, error) {
+	if len(h) == 1 {
+		return h[0], nil
+	}
+	return []string(h), nil
+}
+
+// HookTypes are the hook points of a bump, in the order they run. on_failure only runs when the bump fails.
+type HookTypes struct {
+	PreBump       HookCommands `json:"pre_bump,omitempty" yaml:"pre_bump,omitempty" plain:"pre_bump,omitempty"`
+	PostBump      HookCommands `json:"post_bump,omitempty" yaml:"post_bump,omitempty" plain:"post_bump,omitempty"`
+	PreChangelog  HookCommands `json:"pre_changelog,omitempty" yaml:"pre_changelog,omitempty" plain:"pre_changelog,omitempty"`
+	PostChangelog HookCommands `json:"post_changelog,omitempty" yaml:"post_changelog,omitempty" plain:"post_changelog,omitempty"`
+	PreCommit     HookCommands `json:"pre_commit,omitempty" yaml:"pre_commit,omitempty" plain:"pre_commit,omitempty"`
+	PostTag       HookCommands `json:"post_tag,omitempty" yaml:"post_tag,omitempty" plain:"post_tag,omitempty"`
+	OnFailure     HookCommands `json:"on_failure,omitempty" yaml:"on_failure,omitempty" plain:"on_failure,omitempty"`
+}
+
+// Hook point names, as written in the config files.
+const (
+	HookPreBump       = "pre_bump"
+	HookPostBump      = "post_bump"
+	HookPreChangelog  = "pre_changelog"
+	HookPostChangelog = "post_changelog"
+	HookPreCommit     = "pre_commit"
+	HookPostTag       = "post_tag"
+	HookOnFailure     = "on_failure"
+)
+
+// list returns the commands of the hook points, in the order a bump runs them. A nil HookTypes has none.
+func (h *HookTypes) list() []Hook {
+	hooks := make([]Hook, 0)
+	if h == nil {
+		return hooks
+	}
+
+	val := reflect.ValueOf(*h)
+	for i := 0; i < val.NumField(); i++ {
+		name := jsonKey(val.Type().Field(i))
+		for _, command := range val.Field(i).Interface().(HookCommands) {
+			hooks = append(hooks, Hook{Name: name, Command: command})
+		}
+	}
+	return hooks
+}
+
+// commands returns the commands of the hook point with the given name.
+func (h *HookTypes) commands(name string) HookCommands {
+	if h == nil {
+		return nil
+	}
+	val := reflect.ValueOf(*h)
+	for i := 0; i < val.NumField(); i++ {
+		if jsonKey(val.Type().Field(i)) == name {
+			return val.Field(i).Interface().(HookCommands)
+		}
+	}
+	slog.Debug(fmt.Sprintf("hook %s not found", name))
+	return nil
+}
+
+// HookContext describes the release a hook runs for. Hooks get it in GOMMITIZEN_* environment variables.
+type HookContext struct {
+	PreviousVersion string
+	NewVersion      string
+	Tag             string
+	Increment       string
+	ChangelogFile   string
+
+	// Every tag of the bump, known once all the projects are bumped
+	Tags []string
+
+	// The step that broke the bump and its error, for the on_failure hooks
+	FailedStep string
+	Error      string
+}
+
+// NewHookContext returns the context of the hooks of a bump of the project to newVersion.
+func (v *ConfigVersion) NewHookContext(newVersion string, increment string, changelogFile string) HookContext {
+	return HookContext{
+		PreviousVersion: v.Version,
+		NewVersion:      newVersion,
+		Tag:             v.GetGitTagForVersion(newVersion),
+		Increment:       strings.ToLower(increment),
+		ChangelogFile:   changelogFile,
+	}
+}
+
+// The output of the hooks is streamed as they write it.
+var (
+	hookStdout io.Writer = os.Stdout
+	hookStderr io.Writer = os.Stderr
+)
+
+// GetHooks returns the hook commands defined in the project, in the order a bump runs them.
+func (v *ConfigVersion) GetHooks() []Hook {
+	return v.Hooks.list()
+}
+
+// HookDefinition is a hook command of a project, or of the whole bump when Project is empty, together with the file
+// it is defined in and whether a bump is allowed to run it.
+type HookDefinition struct {
+	Project   string `json:"project,omitempty" yaml:"project,omitempty"`
+	Name      string `json:"name" yaml:"name"`
+	Command   string `json:"command" yaml:"command"`
+	DefinedIn string `json:"defined_in" yaml:"defined_in"`
+	Allowed   bool   `json:"allowed" yaml:"allowed"`
+}
+
+// GetHookDefinitions returns the hook commands of the project with the file each one comes from.
+func (v *ConfigVersion) GetHookDefinitions() []HookDefinition {
+	definitions := make([]HookDefinition, 0)
+	for _, hook := range v.GetHooks() {
+		definedIn := v.GetFilePath()
+		if v.inherited["hooks."+hook.Name] {
+			definedIn = v.GetRepositoryConfigPath()
+		}
+		definitions = append(definitions, HookDefinition{
+			Project:   v.GetDirPath(),
+			Name:      hook.Name,
+			Command:   hook.Command,
+			DefinedIn: definedIn,
+			Allowed:   v.IsHookAllowed(hook.Name),
+		})
+	}
+	return definitions
+}
+
+// IsHookAllowed tells whether the project may run the hook point. Hooks inherited from the repository config always
+// may; hooks defined in the config version file only when the project is in the allowed_hook_paths of the repository
+// config, if it sets them.
+func (v *ConfigVersion) IsHookAllowed(name string) bool {
+	if v.repositoryConfig == nil || v.inherited["hooks."+name] {
+		return true
+	}
+	return v.repositoryConfig.allowsHooksIn(v.dirPath)
+}
+
+// DisableHooks keeps every hook of the project from running.
+func (v *ConfigVersion) DisableHooks() {
+	v.hooksDisabled = true
+}
+
+// GetHookTimeout returns how long a hook may run, zero when there is no limit.
+func (v *ConfigVersion) GetHookTimeout() time.Duration {
+	return parseHookTimeout(v.HookTimeout)
+}
+
+func parseHookTimeout(hookTimeout string) time.Duration {
+	timeout, err := time.ParseDuration(hookTimeout)
+	if err != nil {
+		return 0
+	}
+	return timeout
+}
+
+func validateHookTimeout(hookTimeout string) error {
+	if len(hookTimeout) == 0 {
+		return nil
+	}
+	timeout, err := time.ParseDuration(hookTimeout)
+	if err != nil || timeout < 0 {
+		return fmt.Errorf("invalid hook timeout '%s', expected a duration like 30s or 5m", hookTimeout)
+	}
+	return nil
+}
+
+// validateBumpHooks checks the hooks run once per bump, which have no changelog of their own.
+func validateBumpHooks(hooks HookTypes) error {
+	if len(hooks.PreChangelog) > 0 || len(hooks.PostChangelog) > 0 {
+		return fmt.Errorf("bump_hooks: pre_changelog and post_changelog are only available as project hooks")
+	}
+	return nil
+}
+
+func (v *ConfigVersion) RunPreBump(hookCtx HookContext) error {
+	return v.runHook(HookPreBump, hookCtx)
+}
+
+func (v *ConfigVersion) RunPostBump(hookCtx HookContext) error {
+	return v.runHook(HookPostBump, hookCtx)
+}
+
+func (v *ConfigVersion) RunPreChangelog(hookCtx HookContext) error {
+	return v.runHook(HookPreChangelog, hookCtx)
+}
+
+func (v *ConfigVersion) RunPostChangelog(hookCtx HookContext) error {
+	return v.runHook(HookPostChangelog, hookCtx)
+}
+
+func (v *ConfigVersion) RunPreCommit(hookCtx HookContext) error {
+	return v.runHook(HookPreCommit, hookCtx)
+}
+
+func (v *ConfigVersion) RunPostTag(hookCtx HookContext) error {
+	return v.runHook(HookPostTag, hookCtx)
+}
+
+func (v *ConfigVersion) RunOnFailure(hookCtx HookContext) error {
+	return v.runHook(HookOnFailure, hookCtx)
+}
+
+// runHook runs the commands of a hook point of the project in the project directory.
+func (v *ConfigVersion) runHook(name string, hookCtx HookContext) error {
+	if v.hooksDisabled {
+		slog.Debug(fmt.Sprintf("hooks are disabled, skipping hook %s", name))
+		return nil
+	}
+	if !v.IsHookAllowed(name) {
+		if len(v.Hooks.commands(name)) > 0 {
+			slog.Warn(fmt.Sprintf("Skipping hook %s of %s, the project is not in allowed_hook_paths", name, v.GetFilePath()))
+		}
+		return nil
+	}
+
+	env := []string{
+		"GOMMITIZEN_ALIAS=" + v.Alias,
+		"GOMMITIZEN_PROJECT_DIR=" + v.dirPath,
+		"GOMMITIZEN_PREVIOUS_VERSION=" + hookCtx.PreviousVersion,
+		"GOMMITIZEN_NEW_VERSION=" + hookCtx.NewVersion,
+		"GOMMITIZEN_TAG=" + hookCtx.Tag,
+		"GOMMITIZEN_INCREMENT=" + hookCtx.Increment,
+		"GOMMITIZEN_CHANGELOG_FILE=" + hookCtx.ChangelogFile,
+	}
+	return runHookCommands(name, v.Hooks.commands(name), v.dirPath, v.GetHookTimeout(), append(env, hookCtx.env()...))
+}
+
+// GetBumpHooks returns the hook commands run once per bump, in the order a bump runs them.
+func (rc *RepositoryConfig) GetBumpHooks() []Hook {
+	return rc.BumpHooks.list()
+}
+
+// GetBumpHookDefinitions returns the hook commands run once per bump with the file they are defined in.
+func (rc *RepositoryConfig) GetBumpHookDefinitions() []HookDefinition {
+	definitions := make([]HookDefinition, 0)
+	for _, hook := range rc.GetBumpHooks() {
+		definitions = append(definitions, HookDefinition{Name: hook.Name, Command: hook.Command, DefinedIn: rc.filePath, Allowed: true})
+	}
+	return definitions
+}
+
+// DisableHooks keeps the hooks run once per bump from running.
+func (rc *RepositoryConfig) DisableHooks() {
+	rc.hooksDisabled = true
+}
+
+// allowsHooksIn tells whether the project in dirPath may define its own hooks.
+func (rc *RepositoryConfig) allowsHooksIn(dirPath string) bool {
+	if rc.AllowedHookPaths == nil {
+		return true
+	}
+	relPath, err := filepath.Rel(filepath.Dir(rc.filePath), dirPath)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range rc.AllowedHookPaths {
+		if matchDirGlob(pattern, filepath.ToSlash(relPath)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunBumpHook runs the commands of a hook point of bump_hooks, once for the whole bump, in the directory of the
+// repository config.
+func (rc *RepositoryConfig) RunBumpHook(name string, hookCtx HookContext) error {
+	if rc.hooksDisabled {
+		slog.Debug(fmt.Sprintf("hooks are disabled, skipping bump hook %s", name))
+		return nil
+	}
+	dirPath := filepath.Dir(rc.filePath)
+	return runHookCommands(name, rc.BumpHooks.commands(name), dirPath, parseHookTimeout(rc.HookTimeout), hookCtx.env())
+}
+
+// env returns the environment variables shared by the project hooks and the hooks run once per bump.
+func (c HookContext) env() []string {
+	return []string{
+		"GOMMITIZEN_TAGS=" + strings.Join(c.Tags, " "),
+		"GOMMITIZEN_FAILED_STEP=" + c.FailedStep,
+		"GOMMITIZEN_ERROR=" + c.Error,
+	}
+}
+
+// runHookCommands runs each command of a hook point with bash in dirPath, streaming its output, and stops at the
+// first one that fails.
+func runHookCommands(name string, commands HookCommands, dirPath string, timeout time.Duration, env []string) error {
+	if len(commands) == 0 {
+		slog.Debug(fmt.Sprintf("hook %s is empty", name))
+		return nil
+	}
+
+	for _, command := range commands {
+		if err := runHookCommand(name, command, dirPath, timeout, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runHookCommand(name string, command string, dirPath string, timeout time.Duration, env []string) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "bash", "-c", command)
+	cmd.Dir = dirPath
+	cmd.Env = append(os.Environ(), "GOMMITIZEN_HOOK="+name)
+	cmd.Env = append(cmd.Env, env...)
+	cmd.Stdout = hookStdout
+	cmd.Stderr = hookStderr
+	// A timed out hook is killed together with the processes it started, and the output they hold open is not waited for
+	killProcessGroupOnCancel(cmd)
+	cmd.WaitDelay = time.Second
+
+	slog.Info(fmt.Sprintf("\033[32mRunning hook %s: %s\033[0m", name, command))
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("run hook %s: timed out after %s", name, timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("run hook %s: %v", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func captureHookOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	stdout, stderr := hookStdout, hookStderr
+	hookStdout, hookStderr = &buf, &buf
+	t.Cleanup(func() { hookStdout, hookStderr = stdout, stderr })
+	return &buf
+}
+
+func TestRunHookEnvironmentAndDirectory(t *testing.T) {
+	output := captureHookOutput(t)
+	dirPath := t.TempDir()
+
+	v := NewConfigVersion(dirPath, "1.2.3", "abc123", "api")
+	v.Hooks = &HookTypes{PreBump: HookCommands{`echo "$PWD|$GOMMITIZEN_HOOK|$GOMMITIZEN_ALIAS|$GOMMITIZEN_PREVIOUS_VERSION|$GOMMITIZEN_NEW_VERSION|$GOMMITIZEN_TAG|$GOMMITIZEN_INCREMENT|$GOMMITIZEN_CHANGELOG_FILE"; echo oops >&2`}}
+
+	if err := v.RunPreBump(v.NewHookContext("1.3.0", "minor", "CHANGELOG.md")); err != nil {
+		t.Fatalf("RunPreBump() error = %v", err)
+	}
+
+	expected := dirPath + "|pre_bump|api|1.2.3|1.3.0|1.3.0+api|minor|CHANGELOG.md\noops\n"
+	if output.String() != expected {
+		t.Errorf("expected %q, got %q", expected, output.String())
+	}
+}
+
+func TestRunHookTimeout(t *testing.T) {
+	captureHookOutput(t)
+
+	v := NewConfigVersion(t.TempDir(), "1.2.3", "abc123", "api")
+	v.Hooks = &HookTypes{PostBump: HookCommands{"sleep 5"}}
+	v.HookTimeout = "100ms"
+
+	err := v.RunPostBump(HookContext{})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestRunHookFailure(t *testing.T) {
+	captureHookOutput(t)
+
+	v := NewConfigVersion(t.TempDir(), "1.2.3", "abc123", "api")
+	v.Hooks = &HookTypes{PostChangelog: HookCommands{"exit 3"}}
+
+	if err := v.RunPostChangelog(HookContext{}); err == nil {
+		t.Errorf("expected the hook to fail")
+	}
+	if err := v.RunPreChangelog(HookContext{}); err != nil {
+		t.Errorf("expected an undefined hook to do nothing, got %v", err)
+	}
+}
+
+func TestValidateHookTimeout(t *testing.T) {
+	for _, valid := range []string{"", "30s", "5m"} {
+		if err := validateHookTimeout(valid); err != nil {
+			t.Errorf("expected %q to be valid, got %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"5", "-1s", "soon"} {
+		if err := validateHookTimeout(invalid); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestHookCommandsUnmarshal(t *testing.T) {
+	var fromJSON HookTypes
+	if err := json.Unmarshal([]byte(`{"pre_bump": "make test", "post_tag": ["make docker", "make push"]}`), &fromJSON); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	var fromYAML HookTypes
+	if err := yaml.Unmarshal([]byte("pre_bump: make test\npost_tag:\n  - make docker\n  - make push\n"), &fromYAML); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+
+	expected := HookTypes{PreBump: HookCommands{"make test"}, PostTag: HookCommands{"make docker", "make push"}}
+	if !reflect.DeepEqual(fromJSON, expected) {
+		t.Errorf("expected %+v, got %+v", expected, fromJSON)
+	}
+	if !reflect.DeepEqual(fromYAML, expected) {
+		t.Errorf("expected %+v, got %+v", expected, fromYAML)
+	}
+
+	data, err := json.Marshal(expected)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(data) != `{"pre_bump":"make test","post_tag":["make docker","make push"]}` {
+		t.Errorf("expected single commands saved as strings, got %s", data)
+	}
+}
+
+func TestRunHookCommandsInOrder(t *testing.T) {
+	output := captureHookOutput(t)
+
+	v := NewConfigVersion(t.TempDir(), "1.2.3", "abc123", "api")
+	v.Hooks = &HookTypes{OnFailure: HookCommands{`echo "$GOMMITIZEN_FAILED_STEP"`, "exit 1", "echo unreachable"}}
+
+	err := v.RunOnFailure(HookContext{FailedStep: "git tag", Error: "tag exists"})
+	if err == nil {
+		t.Errorf("expected the hook to fail")
+	}
+	if output.String() != "git tag\n" {
+		t.Errorf("expected the commands to stop at the failing one, got %q", output.String())
+	}
+
+	hooks := v.GetHooks()
+	if len(hooks) != 3 || hooks[2].Name != HookOnFailure || hooks[2].Command != "echo unreachable" {
+		t.Errorf("expected one hook per command, got %+v", hooks)
+	}
+}
+
+func TestRunBumpHook(t *testing.T) {
+	output := captureHookOutput(t)
+	root := t.TempDir()
+	filePath := filepath.Join(root, ".gommitizen.yaml")
+	writeTestFile(t, filePath, "bump_hooks:\n  post_tag: echo \"$PWD|$GOMMITIZEN_HOOK|$GOMMITIZEN_TAGS\"\n")
+
+	rc, err := ReadRepositoryConfig(filePath)
+	if err != nil {
+		t.Fatalf("ReadRepositoryConfig() error = %v", err)
+	}
+	if err := rc.RunBumpHook(HookPostTag, HookContext{Tags: []string{"1.1.0+api", "2.0.0+web"}}); err != nil {
+		t.Fatalf("RunBumpHook() error = %v", err)
+	}
+
+	expected := root + "|post_tag|1.1.0+api 2.0.0+web\n"
+	if output.String() != expected {
+		t.Errorf("expected %q, got %q", expected, output.String())
+	}
+}
+
+func TestReadRepositoryConfigRejectsChangelogBumpHooks(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), ".gommitizen.yaml")
+	writeTestFile(t, filePath, "bump_hooks:\n  pre_changelog: make docs\n")
+
+	if _, err := ReadRepositoryConfig(filePath); err == nil {
+		t.Errorf("expected an error for a changelog hook run once per bump")
+	}
+}
+
+func TestAllowedHookPaths(t *testing.T) {
+	output := captureHookOutput(t)
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git: %v", err)
+	}
+	writeTestFile(t, filepath.Join(root, ".gommitizen.yaml"), `hooks:
+  pre_bump: echo repository
+allowed_hook_paths:
+  - services/*
+`)
+	for _, project := range []string{"services/api", "vendor/lib"} {
+		writeTestFile(t, filepath.Join(root, project, defaultFileName), `{"version": "1.0.0", "hooks": {"post_bump": "echo `+project+`"}}`)
+	}
+
+	api, err := ReadConfigVersion(filepath.Join(root, "services", "api", defaultFileName))
+	if err != nil {
+		t.Fatalf("ReadConfigVersion() error = %v", err)
+	}
+	lib, err := ReadConfigVersion(filepath.Join(root, "vendor", "lib", defaultFileName))
+	if err != nil {
+		t.Fatalf("ReadConfigVersion() error = %v", err)
+	}
+
+	for _, v := range []*ConfigVersion{api, lib} {
+		if err := v.RunPreBump(HookContext{}); err != nil {
+			t.Fatalf("RunPreBump() error = %v", err)
+		}
+		if err := v.RunPostBump(HookContext{}); err != nil {
+			t.Fatalf("RunPostBump() error = %v", err)
+		}
+	}
+	expected := "repository\nservices/api\nrepository\n"
+	if output.String() != expected {
+		t.Errorf("expected %q, got %q", expected, output.String())
+	}
+
+	definitions := lib.GetHookDefinitions()
+	if len(definitions) != 2 {
+		t.Fatalf("expected 2 hook definitions, got %+v", definitions)
+	}
+	if definitions[0].DefinedIn != filepath.Join(root, ".gommitizen.yaml") || !definitions[0].Allowed {
+		t.Errorf("expected an allowed inherited pre_bump hook, got %+v", definitions[0])
+	}
+	if definitions[1].DefinedIn != lib.GetFilePath() || definitions[1].Allowed {
+		t.Errorf("expected a post_bump hook not allowed, got %+v", definitions[1])
+	}
+}
+
+func TestDisableHooks(t *testing.T) {
+	output := captureHookOutput(t)
+
+	v := NewConfigVersion(t.TempDir(), "1.2.3", "abc123", "api")
+	v.Hooks = &HookTypes{PreBump: HookCommands{"echo ran"}}
+	v.DisableHooks()
+
+	if err := v.RunPreBump(HookContext{}); err != nil {
+		t.Fatalf("RunPreBump() error = %v", err)
+	}
+	if output.Len() != 0 {
+		t.Errorf("expected no hook to run, got %q", output.String())
+	}
+}
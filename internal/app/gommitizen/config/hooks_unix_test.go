@@ -0,0 +1,44 @@
+//go:build !windows
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunHookTimeoutKillsChildren(t *testing.T) {
+	captureHookOutput(t)
+
+	dirPath := t.TempDir()
+	pidFile := filepath.Join(dirPath, "child.pid")
+	v := NewConfigVersion(dirPath, "1.2.3", "abc123", "api")
+	v.Hooks = &HookTypes{PostBump: HookCommands{"sleep 30 & echo $! > " + pidFile + "; wait"}}
+	v.HookTimeout = "200ms"
+
+	if err := v.RunPostBump(HookContext{}); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("invalid pid %q: %v", data, err)
+	}
+	// The child is gone once it no longer accepts signals; a zombie waiting for its parent still does, briefly
+	deadline := time.Now().Add(2 * time.Second)
+	for syscall.Kill(pid, 0) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the child %d of the hook to be killed with it", pid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,9 @@
+package config
+
+import (
+	"os/exec"
+)
+
+// killProcessGroupOnCancel leaves cmd as it is, Windows has no process groups to kill: only bash is killed once its
+// context is done.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
@@ -0,0 +1,426 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keyPathRegex matches the key paths of version_files entries: `$.version`, `.appVersion`, `project.version` or
+// `.images[0].tag`.
+var keyPathRegex = regexp.MustCompile(`^(\$\.|\.)?[A-Za-z_][\w-]*(\[[0-9]+\]|\.[A-Za-z_][\w-]*)*$`)
+
+var keyPathSegmentRegex = regexp.MustCompile(`[A-Za-z_][\w-]*|\[[0-9]+\]`)
+
+type keyPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func (s keyPathSegment) String() string {
+	if s.isIndex {
+		return fmt.Sprintf("[%d]", s.index)
+	}
+	return s.key
+}
+
+type keyPath []keyPathSegment
+
+func (p keyPath) String() string {
+	var sb strings.Builder
+	for _, segment := range p {
+		if !segment.isIndex {
+			sb.WriteString(".")
+		}
+		sb.WriteString(segment.String())
+	}
+	return sb.String()
+}
+
+// parseKeyPath returns the key path of a version_files selector for a JSON, YAML or TOML file. Selectors that are
+// not key paths are left to the regular expression form: a bare word like `version` keeps matching `version: x.y.z`
+// anywhere in the file, only selectors starting with `$.` or `.`, or with several keys, address a node.
+func parseKeyPath(fileName string, selector string) (keyPath, bool) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".json", ".yaml", ".yml", ".toml":
+	default:
+		return nil, false
+	}
+	if !keyPathRegex.MatchString(selector) {
+		return nil, false
+	}
+	if !strings.HasPrefix(selector, ".") && !strings.HasPrefix(selector, "$.") && !strings.ContainsAny(selector, ".[") {
+		return nil, false
+	}
+
+	path := make(keyPath, 0)
+	for _, segment := range keyPathSegmentRegex.FindAllString(strings.TrimPrefix(selector, "$"), -1) {
+		if strings.HasPrefix(segment, "[") {
+			index, _ := strconv.Atoi(segment[1 : len(segment)-1])
+			path = append(path, keyPathSegment{index: index, isIndex: true})
+		} else {
+			path = append(path, keyPathSegment{key: segment})
+		}
+	}
+	return path, true
+}
+
+// replaceKeyPath sets the string at path to newVersion. Only the bytes of that value change, so formatting, comments
+// and key order are kept.
+func replaceKeyPath(content []byte, fileName string, path keyPath, newVersion string) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".json":
+		return replaceJSONKey(content, path, newVersion)
+	case ".yaml", ".yml":
+		return replaceYAMLKey(content, path, newVersion)
+	case ".toml":
+		return replaceTOMLKey(content, path, newVersion)
+	}
+	return nil, fmt.Errorf("key paths are not supported for %s", fileName)
+}
+
+func replaceJSONKey(content []byte, path keyPath, newVersion string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	start, end, err := findJSONValue(decoder, path)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %v", path, err)
+	}
+
+	// The value starts after the separator and the blanks that follow the key
+	start += int64(bytes.IndexByte(content[start:end], '"'))
+
+	value, err := json.Marshal(newVersion)
+	if err != nil {
+		return nil, err
+	}
+	return splice(content, int(start), int(end), value), nil
+}
+
+// findJSONValue returns the byte range of the string at path, reading the next value of the decoder. The range may
+// start with the blanks and separator before the value.
+func findJSONValue(decoder *json.Decoder, path keyPath) (int64, int64, error) {
+	start := decoder.InputOffset()
+	token, err := decoder.Token()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(path) == 0 {
+		if _, ok := token.(string); !ok {
+			return 0, 0, fmt.Errorf("value is not a string")
+		}
+		return start, decoder.InputOffset(), nil
+	}
+
+	delim, _ := token.(json.Delim)
+	segment := path[0]
+	switch {
+	case delim == '{' && !segment.isIndex:
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return 0, 0, err
+			}
+			if key == segment.key {
+				return findJSONValue(decoder, path[1:])
+			}
+			if err := skipJSONValue(decoder); err != nil {
+				return 0, 0, err
+			}
+		}
+	case delim == '[' && segment.isIndex:
+		for i := 0; decoder.More(); i++ {
+			if i == segment.index {
+				return findJSONValue(decoder, path[1:])
+			}
+			if err := skipJSONValue(decoder); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	return 0, 0, fmt.Errorf("not found")
+}
+
+func skipJSONValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// jsonMember is the byte range of a member of the top level object of a JSON document: its key, from the opening
+// quote, and its value.
+type jsonMember struct {
+	key        string
+	keyStart   int
+	keyEnd     int
+	valueStart int
+	valueEnd   int
+}
+
+// topLevelJSONMembers returns the members of the top level object of content, in the order they are written.
+func topLevelJSONMembers(content []byte) ([]jsonMember, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, fmt.Errorf("not an object")
+	}
+
+	members := make([]jsonMember, 0)
+	for decoder.More() {
+		// The offset is past the previous value, before the separator and the blanks that precede the key
+		offset := int(decoder.InputOffset())
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		keyEnd := int(decoder.InputOffset())
+		if err := skipJSONValue(decoder); err != nil {
+			return nil, err
+		}
+		valueEnd := int(decoder.InputOffset())
+
+		valueStart := keyEnd + bytes.IndexByte(content[keyEnd:valueEnd], ':') + 1
+		valueStart += len(content[valueStart:valueEnd]) - len(bytes.TrimLeft(content[valueStart:valueEnd], " \t\r\n"))
+		members = append(members, jsonMember{
+			key:        key.(string),
+			keyStart:   offset + bytes.IndexByte(content[offset:keyEnd], '"'),
+			keyEnd:     keyEnd,
+			valueStart: valueStart,
+			valueEnd:   valueEnd,
+		})
+	}
+	return members, nil
+}
+
+// setJSONString sets the member key of the top level object of content to the string value. Only the bytes of the
+// value change. A missing member is added after the member named after, or after the last one, laid out like it. With
+// omitEmpty, an empty value removes the member instead.
+func setJSONString(content []byte, key string, value string, after string, omitEmpty bool) ([]byte, error) {
+	members, err := topLevelJSONMembers(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse json: %v", err)
+	}
+
+	index, anchor := -1, len(members)-1
+	for i, member := range members {
+		if member.key == key {
+			index = i
+		}
+		if member.key == after {
+			anchor = i
+		}
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	remove := omitEmpty && len(value) == 0
+	switch {
+	case index >= 0 && remove:
+		member := members[index]
+		if index > 0 {
+			return splice(content, members[index-1].valueEnd, member.valueEnd, nil), nil
+		}
+		if len(members) > 1 {
+			return splice(content, member.keyStart, members[1].keyStart, nil), nil
+		}
+		return splice(content, member.keyStart, member.valueEnd, nil), nil
+	case index >= 0:
+		return splice(content, members[index].valueStart, members[index].valueEnd, encoded), nil
+	case remove:
+		return content, nil
+	case anchor < 0:
+		return nil, fmt.Errorf("key %s: cannot be added to an empty object", key)
+	}
+
+	// The new member takes the indentation of the anchor and the blanks around its colon
+	member := members[anchor]
+	encodedKey, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+	added := append([]byte(","), content[bytes.LastIndexAny(content[:member.keyStart], ",{")+1:member.keyStart]...)
+	added = append(added, encodedKey...)
+	added = append(added, content[member.keyEnd:member.valueStart]...)
+	added = append(added, encoded...)
+	return splice(content, member.valueEnd, member.valueEnd, added), nil
+}
+
+func replaceYAMLKey(content []byte, path keyPath, newVersion string) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("parse yaml: %v", err)
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 {
+		return nil, fmt.Errorf("key %s: not found", path)
+	}
+
+	node := document.Content[0]
+	for _, segment := range path {
+		node = yamlChild(node, segment)
+		if node == nil {
+			return nil, fmt.Errorf("key %s: not found", path)
+		}
+	}
+	if node.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("key %s: value is not a scalar", path)
+	}
+
+	var quote string
+	switch node.Style {
+	case 0:
+	case yaml.DoubleQuotedStyle:
+		quote = `"`
+	case yaml.SingleQuotedStyle:
+		quote = "'"
+	default:
+		return nil, fmt.Errorf("key %s: unsupported scalar style", path)
+	}
+
+	start := lineColumnOffset(content, node.Line, node.Column)
+	old := []byte(quote + node.Value + quote)
+	if start < 0 || !bytes.HasPrefix(content[start:], old) {
+		return nil, fmt.Errorf("key %s: value cannot be updated in place", path)
+	}
+	return splice(content, start, start+len(old), []byte(quote+newVersion+quote)), nil
+}
+
+func yamlChild(node *yaml.Node, segment keyPathSegment) *yaml.Node {
+	switch {
+	case node.Kind == yaml.MappingNode && !segment.isIndex:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment.key {
+				return node.Content[i+1]
+			}
+		}
+	case node.Kind == yaml.SequenceNode && segment.isIndex:
+		if segment.index < len(node.Content) {
+			return node.Content[segment.index]
+		}
+	}
+	return nil
+}
+
+// lineColumnOffset returns the byte offset of a 1-based line and column, counted in characters, or -1.
+func lineColumnOffset(content []byte, line int, column int) int {
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := bytes.IndexByte(content[offset:], '\n')
+		if next == -1 {
+			return -1
+		}
+		offset += next + 1
+	}
+	for i := 1; i < column; i++ {
+		if offset >= len(content) {
+			return -1
+		}
+		_, size := utf8.DecodeRune(content[offset:])
+		offset += size
+	}
+	return offset
+}
+
+var (
+	tomlTableRegex    = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`)
+	tomlKeyValueRegex = regexp.MustCompile(`^\s*([A-Za-z0-9_\-."' ]+?)\s*=\s*("[^"]*"|'[^']*')`)
+)
+
+// replaceTOMLKey sets the string at path in a TOML file. Tables, dotted keys and quoted keys are supported, inline
+// tables and arrays of tables are not.
+func replaceTOMLKey(content []byte, path keyPath, newVersion string) ([]byte, error) {
+	target := make([]string, 0, len(path))
+	for _, segment := range path {
+		if segment.isIndex {
+			return nil, fmt.Errorf("key %s: indexes are not supported in toml files", path)
+		}
+		target = append(target, segment.key)
+	}
+	targetKey := strings.Join(target, ".")
+
+	table := ""
+	inMultilineString := false
+	offset := 0
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		lineStart := offset
+		offset += len(line)
+
+		text := strings.TrimRight(string(line), "\r\n")
+		if strings.Count(text, `"""`)%2 == 1 || strings.Count(text, `'''`)%2 == 1 {
+			inMultilineString = !inMultilineString
+			continue
+		}
+		if inMultilineString {
+			continue
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(text), "[[") {
+			// Keys of arrays of tables cannot be addressed
+			table = "\x00"
+			continue
+		}
+		if match := tomlTableRegex.FindStringSubmatch(text); match != nil {
+			table = tomlDottedKey(match[1])
+			continue
+		}
+
+		match := tomlKeyValueRegex.FindStringSubmatchIndex(text)
+		if match == nil {
+			continue
+		}
+		key := tomlDottedKey(text[match[2]:match[3]])
+		if len(table) > 0 {
+			key = table + "." + key
+		}
+		if key != targetKey {
+			continue
+		}
+
+		quote := text[match[4] : match[4]+1]
+		value := []byte(quote + newVersion + quote)
+		return splice(content, lineStart+match[4], lineStart+match[5], value), nil
+	}
+
+	return nil, fmt.Errorf("key %s: not found or not a string", path)
+}
+
+// tomlDottedKey normalizes a TOML key like `tool . "poetry"` to `tool.poetry`.
+func tomlDottedKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+func splice(content []byte, start int, end int, value []byte) []byte {
+	result := make([]byte, 0, len(content)-(end-start)+len(value))
+	result = append(result, content[:start]...)
+	result = append(result, value...)
+	return append(result, content[end:]...)
+}
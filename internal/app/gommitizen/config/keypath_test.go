@@ -0,0 +1,214 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseKeyPath(t *testing.T) {
+	tests := []struct {
+		fileName string
+		selector string
+		expected string
+		ok       bool
+	}{
+		{"package.json", "$.version", ".version", true},
+		{"Chart.yaml", ".appVersion", ".appVersion", true},
+		{"pyproject.toml", "project.version", ".project.version", true},
+		{"values.yml", ".images[0].tag", ".images[0].tag", true},
+		{"Chart.yaml", "version", "", false},
+		{"version.txt", ".version", "", false},
+		{"package.json", `"version":\s*"(.*)"`, "", false},
+	}
+
+	for _, tt := range tests {
+		path, ok := parseKeyPath(tt.fileName, tt.selector)
+		if ok != tt.ok {
+			t.Errorf("parseKeyPath(%q, %q): expected ok %t, got %t", tt.fileName, tt.selector, tt.ok, ok)
+			continue
+		}
+		if ok && path.String() != tt.expected {
+			t.Errorf("parseKeyPath(%q, %q): expected %s, got %s", tt.fileName, tt.selector, tt.expected, path)
+		}
+	}
+}
+
+func TestReplaceKeyPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		selector string
+		content  string
+		expected string
+	}{
+		{
+			name:     "json root key",
+			fileName: "package.json",
+			selector: "$.version",
+			content: `{
+  "name": "app",
+  "dependencies": {"version": "1.0.0", "lib": "1.0.0"},
+  "version":   "1.0.0",
+  "scripts": {}
+}
+`,
+			expected: `{
+  "name": "app",
+  "dependencies": {"version": "1.0.0", "lib": "1.0.0"},
+  "version":   "1.1.0",
+  "scripts": {}
+}
+`,
+		},
+		{
+			name:     "json array index",
+			fileName: "manifest.json",
+			selector: ".images[1].tag",
+			content:  `{"images": [{"tag": "1.0.0"}, {"tag": "1.0.0"}]}`,
+			expected: `{"images": [{"tag": "1.0.0"}, {"tag": "1.1.0"}]}`,
+		},
+		{
+			name:     "yaml nested key",
+			fileName: "Chart.yaml",
+			selector: ".appVersion",
+			content: `# Helm chart
+apiVersion: v2
+version: 1.0.0 # chart version
+appVersion: "1.0.0"
+dependencies:
+  - name: redis
+    version: 1.0.0
+`,
+			expected: `# Helm chart
+apiVersion: v2
+version: 1.0.0 # chart version
+appVersion: "1.1.0"
+dependencies:
+  - name: redis
+    version: 1.0.0
+`,
+		},
+		{
+			name:     "yaml sequence",
+			fileName: "Chart.yaml",
+			selector: ".dependencies[0].version",
+			content:  "dependencies:\n  - name: redis\n    version: '1.0.0'\n",
+			expected: "dependencies:\n  - name: redis\n    version: '1.1.0'\n",
+		},
+		{
+			name:     "toml table",
+			fileName: "pyproject.toml",
+			selector: "project.version",
+			content: `[tool.poetry]
+version = "1.0.0"
+
+[project]
+name = "app"
+# the version
+version = "1.0.0"  # keep in sync
+`,
+			expected: `[tool.poetry]
+version = "1.0.0"
+
+[project]
+name = "app"
+# the version
+version = "1.1.0"  # keep in sync
+`,
+		},
+		{
+			name:     "toml dotted key",
+			fileName: "Cargo.toml",
+			selector: "package.version",
+			content:  "package.name = \"app\"\r\npackage.version = '1.0.0'\r\n",
+			expected: "package.name = \"app\"\r\npackage.version = '1.1.0'\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, ok := parseKeyPath(tt.fileName, tt.selector)
+			if !ok {
+				t.Fatalf("expected %q to be a key path", tt.selector)
+			}
+			result, err := replaceKeyPath([]byte(tt.content), tt.fileName, path, "1.1.0")
+			if err != nil {
+				t.Fatalf("replaceKeyPath() error = %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestReplaceKeyPathNotFound(t *testing.T) {
+	tests := []struct {
+		fileName string
+		selector string
+		content  string
+	}{
+		{"package.json", "$.version", `{"name": "app"}`},
+		{"package.json", "$.version", `{"version": 1}`},
+		{"Chart.yaml", ".appVersion", "version: 1.0.0\n"},
+		{"pyproject.toml", "project.version", "[tool]\nversion = \"1.0.0\"\n"},
+	}
+
+	for _, tt := range tests {
+		path, _ := parseKeyPath(tt.fileName, tt.selector)
+		if _, err := replaceKeyPath([]byte(tt.content), tt.fileName, path, "1.1.0"); err == nil {
+			t.Errorf("%s %s: expected an error", tt.fileName, tt.selector)
+		}
+	}
+}
+
+func TestPlanVersionKeyPath(t *testing.T) {
+	dirPath := t.TempDir()
+	writeTestFile(t, filepath.Join(dirPath, "package.json"), `{"version": "1.0.0", "dependencies": {"lib": "1.0.0"}}`)
+
+	v := NewConfigVersion(dirPath, "1.0.0", "abc123", "app")
+	v.VersionFiles = []string{"package.json:$.version"}
+
+	changes, err := v.PlanVersion("1.1.0", "def456")
+	if err != nil {
+		t.Fatalf("PlanVersion() error = %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d", len(changes))
+	}
+	expected := `{"version": "1.1.0", "dependencies": {"lib": "1.0.0"}}`
+	if string(changes[1].After) != expected {
+		t.Errorf("expected %s, got %s", expected, changes[1].After)
+	}
+}
+
+func TestSetJSONString(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		key       string
+		value     string
+		after     string
+		omitEmpty bool
+		expected  string
+	}{
+		{"replace", "{\n  \"version\" : \"1.0.0\"\n}\n", "version", "2.0.0", "", false, "{\n  \"version\" : \"2.0.0\"\n}\n"},
+		{"add after", "{\n  \"version\": \"1.0.0\",\n  \"alias\": \"api\"\n}\n", "commit", "abc", "version", false, "{\n  \"version\": \"1.0.0\",\n  \"commit\": \"abc\",\n  \"alias\": \"api\"\n}\n"},
+		{"add last", "{\"version\": \"1.0.0\"}", "commit", "abc", "", false, "{\"version\": \"1.0.0\",\"commit\": \"abc\"}"},
+		{"remove", "{\n  \"commit\": \"abc\",\n  \"release_commit\": \"def\"\n}", "release_commit", "", "", true, "{\n  \"commit\": \"abc\"\n}"},
+		{"remove first", "{\n  \"release_commit\": \"def\",\n  \"commit\": \"abc\"\n}", "release_commit", "", "", true, "{\n  \"commit\": \"abc\"\n}"},
+		{"remove missing", "{\"commit\": \"abc\"}", "release_commit", "", "", true, "{\"commit\": \"abc\"}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setJSONString([]byte(tt.content), tt.key, tt.value, tt.after, tt.omitEmpty)
+			if err != nil {
+				t.Fatalf("setJSONString() error = %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"path"
 	"reflect"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -14,6 +16,10 @@ type ConfigVersionWrapper struct {
 	FilePath      string                 `json:"file_path" yaml:"file_path" plain:"file_path"`
 	LatestGitTag  string                 `json:"latest_git_tag" yaml:"latest_git_tag" plain:"latest_git_tag"`
 	ConfigVersion map[string]interface{} `json:"config_version" yaml:"config_version" plain:"config_version"`
+
+	// Only shown with every field: the repository config file and the layer each value comes from
+	RepositoryConfig string            `json:"repository_config,omitempty" yaml:"repository_config,omitempty" plain:"repository_config,omitempty"`
+	Sources          map[string]string `json:"sources,omitempty" yaml:"sources,omitempty" plain:"sources,omitempty"`
 }
 
 type Wrapper struct {
@@ -69,6 +75,20 @@ func printConfigVersionsPlain(wrapper Wrapper) (string, error) {
 				sb.WriteString(fmt.Sprintf("    %s: %s\n", key, value))
 			}
 		}
+		if len(cvw.RepositoryConfig) > 0 {
+			sb.WriteString(fmt.Sprintf("  repository_config: %s\n", cvw.RepositoryConfig))
+		}
+		if len(cvw.Sources) > 0 {
+			sb.WriteString("  sources:\n")
+			keys := make([]string, 0, len(cvw.Sources))
+			for key := range cvw.Sources {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				sb.WriteString(fmt.Sprintf("    %s: %s\n", key, cvw.Sources[key]))
+			}
+		}
 	}
 
 	return sb.String(), nil
@@ -90,6 +110,8 @@ func configVersionFilter(configVersions []*ConfigVersion, fields []string, outpu
 
 		if len(fields) == 0 {
 			fields = getAllFieldNames(typ)
+			cvw.RepositoryConfig = configVersion.GetRepositoryConfigPath()
+			cvw.Sources = configVersion.GetSources()
 		}
 
 		for _, field := range fields {
@@ -98,13 +120,22 @@ func configVersionFilter(configVersions []*ConfigVersion, fields []string, outpu
 				continue
 			}
 			xValue := val.FieldByName(field)
-			if xValue.IsValid() && xValue.CanInterface() {
-				xTag := strings.Split(xField.Tag.Get(outputFormat), ",")[0]
-				if xTag == "" {
-					xTag = field
+			if !xValue.IsValid() || !xValue.CanInterface() {
+				continue
+			}
+			// Settings held by pointer, like hooks, show as their value, empty when unset
+			if xValue.Kind() == reflect.Ptr {
+				if xValue.IsNil() {
+					xValue = reflect.Zero(xValue.Type().Elem())
+				} else {
+					xValue = xValue.Elem()
 				}
-				cvw.ConfigVersion[xTag] = xValue.Interface()
 			}
+			xTag := strings.Split(xField.Tag.Get(outputFormat), ",")[0]
+			if xTag == "" {
+				xTag = field
+			}
+			cvw.ConfigVersion[xTag] = xValue.Interface()
 		}
 
 		wrapper.ConfigVersionWrappers = append(wrapper.ConfigVersionWrappers, cvw)
@@ -120,3 +151,165 @@ func getAllFieldNames(typ reflect.Type) []string {
 	}
 	return fields
 }
+
+// ProjectNode is a project in the project tree, with the projects nested in its directory. DirPath is relative to the
+// root of the repository.
+type ProjectNode struct {
+	DirPath               string         `json:"dir_path" yaml:"dir_path"`
+	Alias                 string         `json:"alias" yaml:"alias"`
+	Version               string         `json:"version" yaml:"version"`
+	IncludeNestedProjects bool           `json:"include_nested_projects,omitempty" yaml:"include_nested_projects,omitempty"`
+	Projects              []*ProjectNode `json:"projects,omitempty" yaml:"projects,omitempty"`
+}
+
+type ProjectTreeWrapper struct {
+	Projects []*ProjectNode `json:"projects" yaml:"projects"`
+}
+
+// PrintProjectTree prints the projects as a tree, each under the project whose directory holds it.
+func PrintProjectTree(configVersions []*ConfigVersion, outputFormat string) (string, error) {
+	wrapper := ProjectTreeWrapper{Projects: buildProjectTree(configVersions)}
+
+	switch outputFormat {
+	case "json":
+		data, err := json.MarshalIndent(wrapper, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error marshalling data: %v", err)
+		}
+		return string(data), nil
+	case "yaml":
+		data, err := yaml.Marshal(wrapper)
+		if err != nil {
+			return "", fmt.Errorf("error marshalling data: %v", err)
+		}
+		return string(data), nil
+	case "plain":
+		var sb strings.Builder
+		for _, node := range wrapper.Projects {
+			writeProjectNode(&sb, node, "", "")
+		}
+		return sb.String(), nil
+	}
+
+	return "", fmt.Errorf("unsupported format: %s", outputFormat)
+}
+
+// buildProjectTree returns the projects that are not nested in another one, with their nested projects.
+func buildProjectTree(configVersions []*ConfigVersion) []*ProjectNode {
+	dirPaths := make(map[*ConfigVersion]string, len(configVersions))
+	for _, configVersion := range configVersions {
+		dirPaths[configVersion] = repositoryPath(configVersion.GetDirPath())
+	}
+	sorted := append([]*ConfigVersion{}, configVersions...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return dirPaths[sorted[i]] < dirPaths[sorted[j]]
+	})
+
+	roots := make([]*ProjectNode, 0)
+	// Projects by directory, to find the closest project holding each one
+	nodes := make(map[string]*ProjectNode)
+	for _, configVersion := range sorted {
+		dirPath := dirPaths[configVersion]
+		node := &ProjectNode{
+			DirPath:               dirPath,
+			Alias:                 configVersion.Alias,
+			Version:               configVersion.Version,
+			IncludeNestedProjects: configVersion.IncludeNestedProjects,
+		}
+		nodes[dirPath] = node
+
+		parent := parentProjectNode(nodes, dirPath)
+		if parent == nil {
+			roots = append(roots, node)
+		} else {
+			parent.Projects = append(parent.Projects, node)
+		}
+	}
+	return roots
+}
+
+// parentProjectNode returns the node of the closest project directory above dirPath, or nil.
+func parentProjectNode(nodes map[string]*ProjectNode, dirPath string) *ProjectNode {
+	for dir := dirPath; ; {
+		parent := path.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		if node, ok := nodes[parent]; ok {
+			return node
+		}
+		dir = parent
+	}
+}
+
+func writeProjectNode(sb *strings.Builder, node *ProjectNode, prefix string, childPrefix string) {
+	sb.WriteString(fmt.Sprintf("%s%s (%s) %s", prefix, node.DirPath, node.Alias, node.Version))
+	if node.IncludeNestedProjects {
+		sb.WriteString(" [includes nested projects]")
+	}
+	sb.WriteString("\n")
+
+	for i, child := range node.Projects {
+		if i == len(node.Projects)-1 {
+			writeProjectNode(sb, child, childPrefix+"└── ", childPrefix+"    ")
+		} else {
+			writeProjectNode(sb, child, childPrefix+"├── ", childPrefix+"│   ")
+		}
+	}
+}
+
+// ProjectPaths are the watched paths of a project. DirPath is relative to the root of the repository.
+type ProjectPaths struct {
+	DirPath string        `json:"dir_path" yaml:"dir_path"`
+	Alias   string        `json:"alias" yaml:"alias"`
+	Paths   []WatchedPath `json:"paths" yaml:"paths"`
+}
+
+type ProjectPathsWrapper struct {
+	Projects []ProjectPaths `json:"projects" yaml:"projects"`
+}
+
+// PrintWatchedPaths prints the paths whose commits count for each project, and the paths left out of them.
+func PrintWatchedPaths(configVersions []*ConfigVersion, outputFormat string) (string, error) {
+	wrapper := ProjectPathsWrapper{Projects: make([]ProjectPaths, 0, len(configVersions))}
+	for _, configVersion := range configVersions {
+		wrapper.Projects = append(wrapper.Projects, ProjectPaths{
+			DirPath: repositoryPath(configVersion.GetDirPath()),
+			Alias:   configVersion.Alias,
+			Paths:   configVersion.GetWatchedPaths(),
+		})
+	}
+
+	switch outputFormat {
+	case "json":
+		data, err := json.MarshalIndent(wrapper, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error marshalling data: %v", err)
+		}
+		return string(data), nil
+	case "yaml":
+		data, err := yaml.Marshal(wrapper)
+		if err != nil {
+			return "", fmt.Errorf("error marshalling data: %v", err)
+		}
+		return string(data), nil
+	case "plain":
+		var sb strings.Builder
+		for _, project := range wrapper.Projects {
+			sb.WriteString(fmt.Sprintf("%s (%s)\n", project.DirPath, project.Alias))
+			for _, watched := range project.Paths {
+				sign, from := "+", ""
+				if watched.Ignored {
+					sign = "-"
+				}
+				if watched.Root {
+					from = ", from the repository root"
+				}
+				sb.WriteString(fmt.Sprintf("  %s %s (%s%s)\n", sign, watched.Path, watched.Source, from))
+			}
+		}
+		return sb.String(), nil
+	}
+
+	return "", fmt.Errorf("unsupported format: %s", outputFormat)
+}
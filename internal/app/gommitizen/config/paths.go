@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+// Settings a watched path comes from
+const (
+	WatchedPathSourceDirectory     = "project directory"
+	WatchedPathSourcePaths         = "paths"
+	WatchedPathSourceNestedProject = "nested project"
+	WatchedPathSourceIgnorePaths   = "ignore_paths"
+)
+
+// WatchedPath is a path whose commits count for a project or, when ignored, do not count even if it is under a
+// watched path.
+type WatchedPath struct {
+	// Path is relative to the root of the repository, with forward slashes
+	Path    string `json:"path" yaml:"path"`
+	Ignored bool   `json:"ignored" yaml:"ignored"`
+	// Root is set when the glob starts with a slash, so it is relative to the root of the repository instead of the
+	// project directory
+	Root   bool   `json:"repository_root,omitempty" yaml:"repository_root,omitempty"`
+	Source string `json:"source" yaml:"source"`
+
+	pathspec string
+}
+
+// GetNestedProjects returns the directories of the projects nested in the project, relative to its directory and
+// sorted. They are known to the config versions returned by FindConfigVersions, so that only the discovered projects
+// count.
+func (v *ConfigVersion) GetNestedProjects() []string {
+	return v.nestedProjects
+}
+
+// nestedProjectDirs returns the directories of the config version files nested in dirPath, relative to it and sorted.
+// A project nested in another nested project is left out, its directory being under the other one.
+func nestedProjectDirs(dirPath string, configVersionPaths []string) []string {
+	dirs := make([]string, 0)
+	for _, configVersionPath := range configVersionPaths {
+		relPath, err := filepath.Rel(dirPath, filepath.Dir(configVersionPath))
+		if err != nil || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			continue
+		}
+		dirs = append(dirs, relPath)
+	}
+	sort.Strings(dirs)
+
+	nested := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if !isUnderAny(dir, nested) {
+			nested = append(nested, dir)
+		}
+	}
+	return nested
+}
+
+// GetWatchedPaths returns the paths whose commits count for the project: its directory and the globs of paths, less
+// the directories of its nested projects, unless include_nested_projects is set, and the globs of ignore_paths.
+func (v *ConfigVersion) GetWatchedPaths() []WatchedPath {
+	watched := []WatchedPath{{Path: repositoryPath(v.dirPath), Source: WatchedPathSourceDirectory, pathspec: v.dirPath}}
+	for _, pattern := range v.Paths {
+		watched = append(watched, v.newWatchedPath(pattern, false, WatchedPathSourcePaths))
+	}
+
+	if !v.IncludeNestedProjects {
+		for _, dir := range v.GetNestedProjects() {
+			dirPath := filepath.Join(v.dirPath, dir)
+			watched = append(watched, WatchedPath{
+				Path:     repositoryPath(dirPath),
+				Ignored:  true,
+				Source:   WatchedPathSourceNestedProject,
+				pathspec: git.ExcludePathspec(dirPath),
+			})
+		}
+	}
+
+	for _, pattern := range v.IgnorePaths {
+		watched = append(watched, v.newWatchedPath(pattern, true, WatchedPathSourceIgnorePaths))
+	}
+	return watched
+}
+
+// GetPathspecs returns the git pathspecs of the watched paths of the project.
+func (v *ConfigVersion) GetPathspecs() []string {
+	watched := v.GetWatchedPaths()
+	pathspecs := make([]string, 0, len(watched))
+	for _, watchedPath := range watched {
+		pathspecs = append(pathspecs, watchedPath.pathspec)
+	}
+	return pathspecs
+}
+
+// newWatchedPath returns the watched path of a glob of paths or ignore_paths. The glob is relative to the directory of
+// the project or, when it starts with a slash, to the root of the repository.
+func (v *ConfigVersion) newWatchedPath(pattern string, ignored bool, source string) WatchedPath {
+	magic := []string{git.PathspecGlob}
+	if ignored {
+		magic = append(magic, git.PathspecExclude)
+	}
+
+	if strings.HasPrefix(pattern, "/") {
+		rootPath := path.Clean(strings.TrimLeft(pattern, "/"))
+		return WatchedPath{
+			Path:     rootPath,
+			Ignored:  ignored,
+			Root:     true,
+			Source:   source,
+			pathspec: git.Pathspec(rootPath, append(magic, git.PathspecTop)...),
+		}
+	}
+
+	filePath := filepath.Join(v.dirPath, filepath.FromSlash(pattern))
+	return WatchedPath{
+		Path:     repositoryPath(filePath),
+		Ignored:  ignored,
+		Source:   source,
+		pathspec: git.Pathspec(filepath.ToSlash(filePath), magic...),
+	}
+}
+
+// validatePaths checks the globs of paths and ignore_paths.
+func validatePaths(paths []string, ignorePaths []string) error {
+	settings := []struct {
+		key      string
+		patterns []string
+	}{{"paths", paths}, {"ignore_paths", ignorePaths}}
+
+	for _, setting := range settings {
+		for _, pattern := range setting.patterns {
+			if len(strings.Trim(pattern, "/ ")) == 0 {
+				return fmt.Errorf("invalid %s entry %q: empty path", setting.key, pattern)
+			}
+			for _, part := range strings.Split(pattern, "/") {
+				if _, err := path.Match(part, ""); err != nil {
+					return fmt.Errorf("invalid %s entry %q: %v", setting.key, pattern, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isUnderAny tells whether filePath is one of dirs or under one of them.
+func isUnderAny(filePath string, dirs []string) bool {
+	for _, dir := range dirs {
+		if filePath == dir || strings.HasPrefix(filePath, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+func TestGetPathspecs(t *testing.T) {
+	dirPath := t.TempDir()
+	writeTestFile(t, filepath.Join(dirPath, defaultFileName), `{"version": "1.0.0"}`)
+	writeTestFile(t, filepath.Join(dirPath, "client", defaultFileName), `{"version": "0.1.0"}`)
+	writeTestFile(t, filepath.Join(dirPath, "client", "gen", defaultFileName), `{"version": "0.1.0"}`)
+	writeTestFile(t, filepath.Join(dirPath, "plugins", "auth", defaultFileName), `{"version": "0.1.0"}`)
+
+	configVersions, err := FindConfigVersions(git.NewRepository(dirPath), dirPath, "")
+	if err != nil {
+		t.Fatalf("FindConfigVersions() error = %v", err)
+	}
+	cfg := configVersions[0]
+
+	nested := cfg.GetNestedProjects()
+	expectedNested := []string{"client", filepath.Join("plugins", "auth")}
+	if !reflect.DeepEqual(nested, expectedNested) {
+		t.Errorf("expected %v, got %v", expectedNested, nested)
+	}
+
+	pathspecs := cfg.GetPathspecs()
+	expected := []string{
+		dirPath,
+		":(exclude)" + filepath.Join(dirPath, "client"),
+		":(exclude)" + filepath.Join(dirPath, "plugins", "auth"),
+	}
+	if !reflect.DeepEqual(pathspecs, expected) {
+		t.Errorf("expected %v, got %v", expected, pathspecs)
+	}
+
+	cfg.IncludeNestedProjects = true
+	pathspecs = cfg.GetPathspecs()
+	if !reflect.DeepEqual(pathspecs, []string{dirPath}) {
+		t.Errorf("expected only %s with include_nested_projects, got %v", dirPath, pathspecs)
+	}
+}
+
+func TestGetPathspecsWithPaths(t *testing.T) {
+	dirPath := t.TempDir()
+	writeTestFile(t, filepath.Join(dirPath, defaultFileName),
+		`{"version": "1.0.0", "paths": ["/proto", "../libs/common"], "ignore_paths": ["docs", "**/*.md"]}`)
+
+	cfg, err := ReadConfigVersion(filepath.Join(dirPath, defaultFileName))
+	if err != nil {
+		t.Fatalf("ReadConfigVersion() error = %v", err)
+	}
+	pathspecs := cfg.GetPathspecs()
+	expected := []string{
+		dirPath,
+		":(glob,top)proto",
+		":(glob)" + filepath.ToSlash(filepath.Join(filepath.Dir(dirPath), "libs", "common")),
+		":(glob,exclude)" + filepath.ToSlash(filepath.Join(dirPath, "docs")),
+		":(glob,exclude)" + filepath.ToSlash(filepath.Join(dirPath, "**", "*.md")),
+	}
+	if !reflect.DeepEqual(pathspecs, expected) {
+		t.Errorf("expected %v, got %v", expected, pathspecs)
+	}
+
+	paths, err := PrintWatchedPaths([]*ConfigVersion{cfg}, "plain")
+	if err != nil {
+		t.Fatalf("PrintWatchedPaths() error = %v", err)
+	}
+	for _, line := range []string{"  + proto (paths, from the repository root)\n", "  - " + filepath.ToSlash(filepath.Join(dirPath, "docs")) + " (ignore_paths)\n"} {
+		if !strings.Contains(paths, line) {
+			t.Errorf("expected %q in:\n%s", line, paths)
+		}
+	}
+}
+
+func TestPrintWatchedPathsRelativeToRepository(t *testing.T) {
+	repoPath := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repoPath, ".git"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	dirPath := filepath.Join(repoPath, "services", "api")
+	writeTestFile(t, filepath.Join(dirPath, defaultFileName),
+		`{"version": "1.0.0", "paths": ["/proto", "../../libs/common"], "ignore_paths": ["**/*.md"]}`)
+
+	cfg, err := ReadConfigVersion(filepath.Join(dirPath, defaultFileName))
+	if err != nil {
+		t.Fatalf("ReadConfigVersion() error = %v", err)
+	}
+	paths, err := PrintWatchedPaths([]*ConfigVersion{cfg}, "plain")
+	if err != nil {
+		t.Fatalf("PrintWatchedPaths() error = %v", err)
+	}
+	expected := "services/api ()\n" +
+		"  + services/api (project directory)\n" +
+		"  + proto (paths, from the repository root)\n" +
+		"  + libs/common (paths)\n" +
+		"  - services/api/**/*.md (ignore_paths)\n"
+	if paths != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, paths)
+	}
+}
+
+func TestReadConfigVersionRejectsInvalidPaths(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), defaultFileName)
+
+	for _, content := range []string{`{"version": "1.0.0", "paths": ["/"]}`, `{"version": "1.0.0", "ignore_paths": ["docs/[a"]}`} {
+		writeTestFile(t, filePath, content)
+		if _, err := ReadConfigVersion(filePath); err == nil {
+			t.Errorf("expected an error for %s", content)
+		}
+	}
+}
+
+func TestPrintProjectTree(t *testing.T) {
+	repoPath := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repoPath, ".git"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	configVersions := []*ConfigVersion{
+		NewConfigVersion(filepath.Join(repoPath, "services", "web"), "2.0.0", "", "web"),
+		NewConfigVersion(filepath.Join(repoPath, "services", "api", "client"), "0.3.0", "", "client"),
+		NewConfigVersion(filepath.Join(repoPath, "services", "api"), "1.2.0", "", "api"),
+		NewConfigVersion(filepath.Join(repoPath, "services", "api", "client", "gen"), "0.1.0", "", "gen"),
+	}
+	configVersions[2].IncludeNestedProjects = true
+
+	tree, err := PrintProjectTree(configVersions, "plain")
+	if err != nil {
+		t.Fatalf("PrintProjectTree() error = %v", err)
+	}
+	expected := "services/api (api) 1.2.0 [includes nested projects]\n" +
+		"└── services/api/client (client) 0.3.0\n" +
+		"    └── services/api/client/gen (gen) 0.1.0\n" +
+		"services/web (web) 2.0.0\n"
+	if tree != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, tree)
+	}
+}
+
+func TestGetNestedProjectsSkipsUndiscoveredProjects(t *testing.T) {
+	dirPath := t.TempDir()
+	writeTestFile(t, filepath.Join(dirPath, ".gommitizen.yaml"), "discovery:\n  exclude:\n    - \"**/third_party\"\n")
+	writeTestFile(t, filepath.Join(dirPath, defaultFileName), `{"version": "1.0.0"}`)
+	writeTestFile(t, filepath.Join(dirPath, "client", defaultFileName), `{"version": "0.1.0"}`)
+	writeTestFile(t, filepath.Join(dirPath, "third_party", "lib", defaultFileName), `{"version": "2.0.0"}`)
+
+	configVersions, err := FindConfigVersions(git.NewRepository(dirPath), dirPath, "")
+	if err != nil {
+		t.Fatalf("FindConfigVersions() error = %v", err)
+	}
+	if len(configVersions) != 2 {
+		t.Fatalf("expected the excluded project to be left out, got %d projects", len(configVersions))
+	}
+
+	expected := []string{dirPath, ":(exclude)" + filepath.Join(dirPath, "client")}
+	if pathspecs := configVersions[0].GetPathspecs(); !reflect.DeepEqual(pathspecs, expected) {
+		t.Errorf("expected the commits of the excluded project to count for its parent, %v, got %v", expected, pathspecs)
+	}
+}
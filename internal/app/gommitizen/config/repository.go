@@ -0,0 +1,332 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/changelog"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
+)
+
+const (
+	SourceDefault    = "default"
+	SourceRepository = "repository"
+	SourceProject    = "project"
+)
+
+// repositoryConfigFileNames are the names of the repository config file, in order of preference.
+var repositoryConfigFileNames = []string{".gommitizen.yaml", ".gommitizen.yml"}
+
+// RepositoryConfig holds the settings shared by every project of a repository. The project settings are defaults that
+// each config version inherits unless it sets them itself, the rest apply to the repository as a whole.
+type RepositoryConfig struct {
+	filePath      string
+	hooksDisabled bool
+
+	// Project defaults
+	TagFormat             TagFormat `json:"tag_format,omitempty" yaml:"tag_format,omitempty"`
+	VersionSource         string    `json:"version_source,omitempty" yaml:"version_source,omitempty"`
+	Hooks                 HookTypes `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	HookTimeout           string    `json:"hook_timeout,omitempty" yaml:"hook_timeout,omitempty"`
+	UpdateChangelogOnBump *bool     `json:"update_changelog_on_bump,omitempty" yaml:"update_changelog_on_bump,omitempty"`
+	ChangelogTemplate     string    `json:"changelog_template,omitempty" yaml:"changelog_template,omitempty"`
+	ChangelogFormats      []string  `json:"changelog_formats,omitempty" yaml:"changelog_formats,omitempty"`
+	IncludeNestedProjects *bool     `json:"include_nested_projects,omitempty" yaml:"include_nested_projects,omitempty"`
+
+	ChangeTypes conventionalcommits.ChangeTypes `json:"change_types,omitempty" yaml:"change_types,omitempty"`
+
+	// Repository settings
+	BumpMessage string                         `json:"bump_message,omitempty" yaml:"bump_message,omitempty"`
+	BumpHooks   HookTypes                      `json:"bump_hooks,omitempty" yaml:"bump_hooks,omitempty"`
+	Discovery   Discovery                      `json:"discovery,omitempty" yaml:"discovery,omitempty"`
+	Check       conventionalcommits.CheckRules `json:"check,omitempty" yaml:"check,omitempty"`
+	// Links of the changelogs to the forge of the repository
+	ChangelogLinks changelog.Links `json:"changelog_links,omitempty" yaml:"changelog_links,omitempty"`
+	// Path of the changelog listing every bump of the repository, relative to the repository config. Only the
+	// changelogs of the projects are written when it is not set.
+	RootChangelog string `json:"root_changelog,omitempty" yaml:"root_changelog,omitempty"`
+
+	// Globs of the project directories whose config version file may define hooks, relative to the repository
+	// config. Every project may when it is not set.
+	AllowedHookPaths []string `json:"allowed_hook_paths,omitempty" yaml:"allowed_hook_paths,omitempty"`
+}
+
+// Discovery configures how projects are found in the repository. Globs and depth are relative to the directory
+// gommitizen runs in.
+type Discovery struct {
+	Include  []string `json:"include,omitempty" yaml:"include,omitempty"`
+	Exclude  []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	MaxDepth int      `json:"max_depth,omitempty" yaml:"max_depth,omitempty"`
+}
+
+// FindRepositoryConfig walks up from dirPath, up to the root of the git repository, looking for a repository config
+// file. It returns an empty config when there is none.
+func FindRepositoryConfig(dirPath string) (*RepositoryConfig, error) {
+	current, err := filepath.Abs(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("find repository config: %v", err)
+	}
+
+	for {
+		for _, fileName := range repositoryConfigFileNames {
+			filePath := filepath.Join(current, fileName)
+			if _, err := os.Stat(filePath); err == nil {
+				return ReadRepositoryConfig(filePath)
+			}
+		}
+
+		// The repository root is the last place to look at
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+
+	return &RepositoryConfig{}, nil
+}
+
+func ReadRepositoryConfig(filePath string) (*RepositoryConfig, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("read file %s: %v", filePath, err)
+	}
+
+	slog.Debug(fmt.Sprintf("reading repository config in %s with data:\n%s", filePath, string(data)))
+
+	var rc RepositoryConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&rc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unmarshal yaml %s: %v", filePath, err)
+	}
+
+	if len(rc.TagFormat) > 0 {
+		if err := rc.TagFormat.Validate(); err != nil {
+			return nil, fmt.Errorf("repository config %s: %v", filePath, err)
+		}
+	}
+	if err := validateVersionSource(rc.VersionSource); err != nil {
+		return nil, fmt.Errorf("repository config %s: %v", filePath, err)
+	}
+	if err := validateHookTimeout(rc.HookTimeout); err != nil {
+		return nil, fmt.Errorf("repository config %s: %v", filePath, err)
+	}
+	if err := validateBumpHooks(rc.BumpHooks); err != nil {
+		return nil, fmt.Errorf("repository config %s: %v", filePath, err)
+	}
+	if err := rc.ChangeTypes.Validate(); err != nil {
+		return nil, fmt.Errorf("repository config %s: %v", filePath, err)
+	}
+	if err := rc.Check.Validate(); err != nil {
+		return nil, fmt.Errorf("repository config %s: %v", filePath, err)
+	}
+	if err := changelog.ValidateFormats(rc.ChangelogFormats); err != nil {
+		return nil, fmt.Errorf("repository config %s: %v", filePath, err)
+	}
+	if err := rc.ChangelogLinks.Validate(); err != nil {
+		return nil, fmt.Errorf("repository config %s: %v", filePath, err)
+	}
+
+	rc.filePath = filePath
+	return &rc, nil
+}
+
+// GetFilePath returns the path of the repository config file, empty when the repository has none.
+func (rc *RepositoryConfig) GetFilePath() string {
+	return rc.filePath
+}
+
+// GetBumpMessage returns the template of the bump commit message, where {tags} is replaced by the new tags.
+func (rc *RepositoryConfig) GetBumpMessage() string {
+	return rc.BumpMessage
+}
+
+// GetChangelogLinks returns the links to the forge set in the repository config. Those not set are derived from the
+// origin remote.
+func (rc *RepositoryConfig) GetChangelogLinks() changelog.Links {
+	return rc.ChangelogLinks
+}
+
+// GetRootChangelogPath returns the path of the root changelog, empty when the repository has none.
+func (rc *RepositoryConfig) GetRootChangelogPath() string {
+	if len(rc.RootChangelog) == 0 || filepath.IsAbs(rc.RootChangelog) {
+		return rc.RootChangelog
+	}
+	return filepath.Join(filepath.Dir(rc.filePath), rc.RootChangelog)
+}
+
+// GetChangeTypes returns the change types of the repository, the default ones when none are configured.
+func (rc *RepositoryConfig) GetChangeTypes() conventionalcommits.ChangeTypes {
+	if len(rc.ChangeTypes) > 0 {
+		return rc.ChangeTypes
+	}
+	return conventionalcommits.DefaultChangeTypes()
+}
+
+// inherit fills the settings the project does not set with the defaults of the repository config. present holds the
+// keys found in the config version file. The keys taken from the repository are recorded so they are not saved into
+// the config version file and so their origin can be shown.
+func (rc *RepositoryConfig) inherit(v *ConfigVersion, present map[string]json.RawMessage) error {
+	presentHooks := make(map[string]json.RawMessage)
+	if raw, ok := present["hooks"]; ok {
+		if err := json.Unmarshal(raw, &presentHooks); err != nil {
+			return fmt.Errorf("unmarshal hooks: %v", err)
+		}
+	}
+
+	v.repositoryConfig = rc
+	v.inherited = make(map[string]bool)
+
+	if _, ok := present["tag_format"]; !ok && len(rc.TagFormat) > 0 {
+		v.TagFormat = rc.TagFormat
+		v.inherited["tag_format"] = true
+	}
+	if _, ok := present["version_source"]; !ok && len(rc.VersionSource) > 0 {
+		v.VersionSource = rc.VersionSource
+		v.inherited["version_source"] = true
+	}
+	if _, ok := present["update_changelog_on_bump"]; !ok && rc.UpdateChangelogOnBump != nil {
+		v.UpdateChangelogOnBump = *rc.UpdateChangelogOnBump
+		v.inherited["update_changelog_on_bump"] = true
+	}
+	if _, ok := present["changelog_template"]; !ok && len(rc.ChangelogTemplate) > 0 {
+		v.ChangelogTemplate = rc.ChangelogTemplate
+		v.inherited["changelog_template"] = true
+	}
+	if _, ok := present["changelog_formats"]; !ok && len(rc.ChangelogFormats) > 0 {
+		v.ChangelogFormats = rc.ChangelogFormats
+		v.inherited["changelog_formats"] = true
+	}
+	if _, ok := present["include_nested_projects"]; !ok && rc.IncludeNestedProjects != nil {
+		v.IncludeNestedProjects = *rc.IncludeNestedProjects
+		v.inherited["include_nested_projects"] = true
+	}
+
+	if _, ok := present["hook_timeout"]; !ok && len(rc.HookTimeout) > 0 {
+		v.HookTimeout = rc.HookTimeout
+		v.inherited["hook_timeout"] = true
+	}
+	if _, ok := present["change_types"]; !ok && len(rc.ChangeTypes) > 0 {
+		v.ChangeTypes = rc.ChangeTypes
+		v.inherited["change_types"] = true
+	}
+
+	repositoryHooks := reflect.ValueOf(rc.Hooks)
+	for i := 0; i < repositoryHooks.NumField(); i++ {
+		key := jsonKey(repositoryHooks.Type().Field(i))
+		if _, ok := presentHooks[key]; ok || repositoryHooks.Field(i).IsZero() {
+			continue
+		}
+		if v.Hooks == nil {
+			v.Hooks = &HookTypes{}
+		}
+		reflect.ValueOf(v.Hooks).Elem().Field(i).Set(repositoryHooks.Field(i))
+		v.inherited["hooks."+key] = true
+	}
+
+	return nil
+}
+
+// GetRepositoryConfigPath returns the path of the repository config the project inherits from, empty when there is
+// none.
+func (v *ConfigVersion) GetRepositoryConfigPath() string {
+	if v.repositoryConfig == nil {
+		return ""
+	}
+	return v.repositoryConfig.GetFilePath()
+}
+
+// GetSources returns, for each setting of the project, the layer its value comes from: the config version file of
+// the project, the repository config or the built-in default.
+func (v *ConfigVersion) GetSources() map[string]string {
+	sources := make(map[string]string)
+
+	val := reflect.ValueOf(v).Elem()
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := jsonKey(field)
+		if field.Type == reflect.TypeOf(&HookTypes{}) {
+			hooks := reflect.ValueOf(HookTypes{})
+			if !val.Field(i).IsNil() {
+				hooks = val.Field(i).Elem()
+			}
+			for j := 0; j < hooks.NumField(); j++ {
+				hookKey := key + "." + jsonKey(hooks.Type().Field(j))
+				sources[hookKey] = v.sourceOf(hookKey, hooks.Field(j))
+			}
+			continue
+		}
+		sources[key] = v.sourceOf(key, val.Field(i))
+	}
+
+	return sources
+}
+
+func (v *ConfigVersion) sourceOf(key string, value reflect.Value) string {
+	if v.inherited[key] {
+		return SourceRepository
+	}
+	if _, ok := v.present[key]; ok || !value.IsZero() {
+		return SourceProject
+	}
+	return SourceDefault
+}
+
+// withoutInherited returns a copy of the config version without the values inherited from the repository config,
+// which is what the config version file holds.
+func (v *ConfigVersion) withoutInherited() ConfigVersion {
+	own := *v
+	if len(v.inherited) == 0 {
+		return own
+	}
+
+	val := reflect.ValueOf(&own).Elem()
+	var hooks HookTypes
+	if own.Hooks != nil {
+		hooks = *own.Hooks
+	}
+	for key := range v.inherited {
+		if hookKey, ok := strings.CutPrefix(key, "hooks."); ok {
+			zeroFieldByJSONKey(reflect.ValueOf(&hooks).Elem(), hookKey)
+		} else {
+			zeroFieldByJSONKey(val, key)
+		}
+	}
+
+	// Hooks that are all inherited leave no hooks in the file, unless it has them
+	own.Hooks = &hooks
+	if _, ok := v.present["hooks"]; !ok && reflect.ValueOf(hooks).IsZero() {
+		own.Hooks = nil
+	}
+	return own
+}
+
+func zeroFieldByJSONKey(val reflect.Value, key string) {
+	for i := 0; i < val.NumField(); i++ {
+		if jsonKey(val.Type().Field(i)) == key {
+			val.Field(i).Set(reflect.Zero(val.Field(i).Type()))
+			return
+		}
+	}
+}
+
+func jsonKey(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+func writeTestFile(t *testing.T, filePath string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", filePath, err)
+	}
+}
+
+func TestReadConfigVersionInheritsRepositoryConfig(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git: %v", err)
+	}
+	writeTestFile(t, filepath.Join(root, ".gommitizen.yaml"), `tag_format: "{alias}/v{version}"
+update_changelog_on_bump: true
+hooks:
+  pre_bump: make test
+  post_bump: make release
+`)
+	projectPath := filepath.Join(root, "services", "api", defaultFileName)
+	writeTestFile(t, projectPath, `{
+  "version": "1.0.0",
+  "commit": "abc123",
+  "version_files": [],
+  "alias": "api",
+  "update_changelog_on_bump": false,
+  "hooks": {"post_bump": "echo done"}
+}`)
+
+	v, err := ReadConfigVersion(projectPath)
+	if err != nil {
+		t.Fatalf("ReadConfigVersion() error = %v", err)
+	}
+
+	if v.TagFormat != "{alias}/v{version}" {
+		t.Errorf("expected inherited tag format, got %s", v.TagFormat)
+	}
+	if v.UpdateChangelogOnBump {
+		t.Errorf("expected update_changelog_on_bump overridden by the project")
+	}
+	if !reflect.DeepEqual(v.Hooks.PreBump, HookCommands{"make test"}) || !reflect.DeepEqual(v.Hooks.PostBump, HookCommands{"echo done"}) {
+		t.Errorf("expected merged hooks, got %+v", v.Hooks)
+	}
+	if v.GetRepositoryConfigPath() != filepath.Join(root, ".gommitizen.yaml") {
+		t.Errorf("expected repository config path, got %s", v.GetRepositoryConfigPath())
+	}
+
+	expectedSources := map[string]string{
+		"version":                  SourceProject,
+		"tag_format":               SourceRepository,
+		"version_source":           SourceDefault,
+		"update_changelog_on_bump": SourceProject,
+		"hooks.pre_bump":           SourceRepository,
+		"hooks.post_bump":          SourceProject,
+		"hooks.pre_changelog":      SourceDefault,
+	}
+	sources := v.GetSources()
+	for key, expected := range expectedSources {
+		if sources[key] != expected {
+			t.Errorf("expected source of %s to be %s, got %s", key, expected, sources[key])
+		}
+	}
+
+	data, err := v.marshal()
+	if err != nil {
+		t.Fatalf("marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), "tag_format") || strings.Contains(string(data), "make test") {
+		t.Errorf("expected inherited values left out of the config version file, got:\n%s", data)
+	}
+	if !strings.Contains(string(data), "echo done") {
+		t.Errorf("expected project hook saved, got:\n%s", data)
+	}
+}
+
+func TestGetChangelogTemplatePath(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git: %v", err)
+	}
+	writeTestFile(t, filepath.Join(root, ".gommitizen.yaml"), "changelog_template: templates/changelog.tpl\n")
+	inheritedPath := filepath.Join(root, "services", "api", defaultFileName)
+	writeTestFile(t, inheritedPath, `{"version": "1.0.0", "commit": "", "version_files": [], "alias": "api"}`)
+	ownPath := filepath.Join(root, "services", "web", defaultFileName)
+	writeTestFile(t, ownPath, `{"version": "1.0.0", "commit": "", "version_files": [], "alias": "web", "changelog_template": "changelog.tpl"}`)
+
+	tests := []struct {
+		filePath string
+		expected string
+	}{
+		{inheritedPath, filepath.Join(root, "templates", "changelog.tpl")},
+		{ownPath, filepath.Join(root, "services", "web", "changelog.tpl")},
+	}
+
+	for _, tt := range tests {
+		v, err := ReadConfigVersion(tt.filePath)
+		if err != nil {
+			t.Fatalf("ReadConfigVersion() error = %v", err)
+		}
+		if v.GetChangelogTemplatePath() != tt.expected {
+			t.Errorf("expected template %s, got %s", tt.expected, v.GetChangelogTemplatePath())
+		}
+	}
+
+	if path := NewConfigVersion(root, "1.0.0", "", "app").GetChangelogTemplatePath(); path != "" {
+		t.Errorf("expected the built-in template, got %s", path)
+	}
+}
+
+func TestFindRepositoryConfigStopsAtRepositoryRoot(t *testing.T) {
+	outer := t.TempDir()
+	writeTestFile(t, filepath.Join(outer, ".gommitizen.yaml"), "bump_message: \"chore: release {tags}\"\n")
+	root := filepath.Join(outer, "repo")
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git: %v", err)
+	}
+
+	rc, err := FindRepositoryConfig(filepath.Join(root))
+	if err != nil {
+		t.Fatalf("FindRepositoryConfig() error = %v", err)
+	}
+	if rc.GetFilePath() != "" {
+		t.Errorf("expected no repository config outside the repository, got %s", rc.GetFilePath())
+	}
+}
+
+func TestReadRepositoryConfigRejectsUnknownKeys(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), ".gommitizen.yaml")
+	writeTestFile(t, filePath, "tag_fromat: \"v{version}\"\n")
+
+	if _, err := ReadRepositoryConfig(filePath); err == nil {
+		t.Errorf("expected an error for an unknown key")
+	}
+}
+
+func TestReadRepositoryConfigChangelogLinks(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), ".gommitizen.yaml")
+	writeTestFile(t, filePath, "changelog_links:\n  forge: gitlab\n  url: https://git.example.com/team/repo\n")
+
+	rc, err := ReadRepositoryConfig(filePath)
+	if err != nil {
+		t.Fatalf("ReadRepositoryConfig() error = %v", err)
+	}
+	if links := rc.GetChangelogLinks(); links.Forge != "gitlab" || links.URL != "https://git.example.com/team/repo" {
+		t.Errorf("expected gitlab links to git.example.com, got %+v", links)
+	}
+
+	writeTestFile(t, filePath, "changelog_links:\n  forge: sourcehut\n")
+	if _, err := ReadRepositoryConfig(filePath); err == nil {
+		t.Errorf("expected an error for an unknown forge")
+	}
+}
+
+func TestReadRepositoryConfigChangelogFormats(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), ".gommitizen.yaml")
+	writeTestFile(t, filePath, "changelog_formats: [markdown, json]\n")
+
+	rc, err := ReadRepositoryConfig(filePath)
+	if err != nil {
+		t.Fatalf("ReadRepositoryConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(rc.ChangelogFormats, []string{"markdown", "json"}) {
+		t.Errorf("expected markdown and json, got %v", rc.ChangelogFormats)
+	}
+
+	writeTestFile(t, filePath, "changelog_formats: [markdown, keepachangelog]\n")
+	if _, err := ReadRepositoryConfig(filePath); err == nil {
+		t.Errorf("expected an error for two formats writing CHANGELOG.md")
+	}
+}
+
+func TestFindConfigVersionFilePathDiscoveryExclude(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git: %v", err)
+	}
+	writeTestFile(t, filepath.Join(root, ".gommitizen.yaml"), "discovery:\n  exclude:\n    - vendor\n    - examples/*\n")
+	writeTestFile(t, filepath.Join(root, "api", defaultFileName), "{}")
+	writeTestFile(t, filepath.Join(root, "vendor", "lib", defaultFileName), "{}")
+	writeTestFile(t, filepath.Join(root, "examples", "demo", defaultFileName), "{}")
+
+	paths, err := FindConfigVersionFilePath(git.NewRepository(root), root)
+	if err != nil {
+		t.Fatalf("FindConfigVersionFilePath() error = %v", err)
+	}
+	if len(paths) != 1 || paths[0] != filepath.Join(root, "api", defaultFileName) {
+		t.Errorf("expected only the api project, got %v", paths)
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// replaceVersion replaces the version in every line matching substring and returns the new content with the number of
+// versions replaced. substring is either a name, matched as `name: x.y.z` or `name = x.y.z`, or a regular expression
+// whose first group is the version. Only the bytes of the version change: line endings, very long lines and the end
+// of the file are kept as they are.
+func replaceVersion(content []byte, substring, newVersion string) ([]byte, int, error) {
+	// Regular expression to find the version in the file
+	regularExpression := ""
+	validRegexp, err := isARegExp(substring)
+	// Check if the substring is a regular expression that compiles
+	if err != nil {
+		return nil, 0, err
+	}
+	if validRegexp { // If it is a regular expression, use it as is
+		regularExpression = substring
+	} else { // If it is a literal string, use it as a word boundary
+		regularExpression = fmt.Sprintf(`(?i)\b%s\b\s*[:=]\s*([0-9]+\.[0-9]+\.[0-9]+(?:-[0-9A-Za-z.-]+)?)`, substring)
+	}
+	versionRegex, err := regexp.Compile(regularExpression)
+	if err != nil {
+		return nil, 0, err
+	}
+	if versionRegex.NumSubexp() == 0 {
+		return nil, 0, fmt.Errorf("regular expression `%s` has no group for the version", substring)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(content))
+	matches := 0
+	for offset := 0; offset < len(content); {
+		end := bytes.IndexByte(content[offset:], '\n')
+		if end == -1 {
+			end = len(content)
+		} else {
+			end += offset
+		}
+		// Patterns anchored with $ must match before a CRLF too
+		line := bytes.TrimSuffix(content[offset:end], []byte("\r"))
+
+		match := versionRegex.FindSubmatchIndex(line)
+		if match != nil && match[2] >= 0 {
+			start, stop := match[2], match[3]
+			for start < stop && isBlank(line[start]) {
+				start++
+			}
+			for stop > start && isBlank(line[stop-1]) {
+				stop--
+			}
+			buf.Write(line[:start])
+			buf.WriteString(newVersion)
+			buf.Write(content[offset+stop : end])
+			matches++
+		} else {
+			buf.Write(content[offset:end])
+		}
+
+		if end < len(content) {
+			buf.WriteByte('\n')
+		}
+		offset = end + 1
+	}
+
+	return buf.Bytes(), matches, nil
+}
+
+func isBlank(b byte) bool {
+	return b == ' ' || b == '\t'
+}
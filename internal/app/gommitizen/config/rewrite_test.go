@@ -0,0 +1,94 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReplaceVersionPreservesBytes(t *testing.T) {
+	longLine := "var bundle = \"" + strings.Repeat("x", 100*1024) + "\";"
+	tests := []struct {
+		name      string
+		substring string
+		content   string
+		expected  string
+		matches   int
+	}{
+		{
+			name:      "crlf line endings",
+			substring: "version",
+			content:   "name: app\r\nversion: 1.0.0\r\nother: 1\r\n",
+			expected:  "name: app\r\nversion: 1.1.0\r\nother: 1\r\n",
+			matches:   1,
+		},
+		{
+			name:      "no trailing newline",
+			substring: "version",
+			content:   "name: app\nversion: 1.0.0",
+			expected:  "name: app\nversion: 1.1.0",
+			matches:   1,
+		},
+		{
+			name:      "long lines",
+			substring: "version",
+			content:   longLine + "\nversion = 1.0.0\n" + longLine,
+			expected:  longLine + "\nversion = 1.1.0\n" + longLine,
+			matches:   1,
+		},
+		{
+			name:      "regular expression anchored before crlf",
+			substring: `^VERSION=([0-9]+\.[0-9]+\.[0-9]+)$`,
+			content:   "VERSION=1.0.0\r\nOTHER=1.0.0\r\nVERSION=1.0.0\r\n",
+			expected:  "VERSION=1.1.0\r\nOTHER=1.0.0\r\nVERSION=1.1.0\r\n",
+			matches:   2,
+		},
+		{
+			name:      "only the captured version changes",
+			substring: `image: app:([0-9.]+)`,
+			content:   "image: app:1.0.0 # app 1.0.0\n",
+			expected:  "image: app:1.1.0 # app 1.0.0\n",
+			matches:   1,
+		},
+		{
+			name:      "no match",
+			substring: "version",
+			content:   "name: app\n",
+			expected:  "name: app\n",
+			matches:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, matches, err := replaceVersion([]byte(tt.content), tt.substring, "1.1.0")
+			if err != nil {
+				t.Fatalf("replaceVersion() error = %v", err)
+			}
+			if matches != tt.matches {
+				t.Errorf("expected %d matches, got %d", tt.matches, matches)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestReplaceVersionWithoutGroup(t *testing.T) {
+	if _, _, err := replaceVersion([]byte("VERSION=1.0.0\n"), `^VERSION=.*$`, "1.1.0"); err == nil {
+		t.Errorf("expected an error for a regular expression without group")
+	}
+}
+
+func TestPlanVersionFailsWithoutMatch(t *testing.T) {
+	dirPath := t.TempDir()
+	writeTestFile(t, filepath.Join(dirPath, "Chart.yaml"), "appVersion: 1.0.0\n")
+
+	v := NewConfigVersion(dirPath, "1.0.0", "abc123", "app")
+	v.VersionFiles = []string{"Chart.yaml:appVersion", "Chart.yaml:chartVersion"}
+
+	if _, err := v.PlanVersion("1.1.0", "def456"); err == nil {
+		t.Errorf("expected an error for an entry matching nothing")
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+const (
+	// VersionSourceFile reads the current version and the commit of the last bump from the config version file.
+	VersionSourceFile = "file"
+	// VersionSourceTag reads them from the latest git tag of the project, the config version file only holds
+	// settings.
+	VersionSourceTag = "tag"
+
+	initialVersion = "0.0.0"
+)
+
+func validateVersionSource(versionSource string) error {
+	switch versionSource {
+	case "", VersionSourceFile, VersionSourceTag:
+		return nil
+	}
+	return fmt.Errorf("invalid version_source %q, supported values: %s, %s", versionSource, VersionSourceFile, VersionSourceTag)
+}
+
+// IsVersionFromTags tells whether the current version of the project comes from its git tags.
+func (v *ConfigVersion) IsVersionFromTags() bool {
+	return v.VersionSource == VersionSourceTag
+}
+
+// ResolveVersion loads the current version and commit from the git tags of the project when its version source is
+// tag. The latest tag that follows the tag format of the project gives both. Without tags, the version and commit of
+// the config version file are kept, starting at 0.0.0 from the first commit when the file has none.
+func (v *ConfigVersion) ResolveVersion(repo git.Repository) error {
+	if !v.IsVersionFromTags() {
+		return nil
+	}
+
+	if len(v.Version) == 0 {
+		v.Version = initialVersion
+	}
+	if len(v.Commit) == 0 {
+		firstCommit, err := repo.GetFirstCommit()
+		if err != nil {
+			return fmt.Errorf("resolve version of %s: %v", v.GetDirPath(), err)
+		}
+		v.Commit = firstCommit
+	}
+
+	tags, err := repo.GetTags()
+	if err != nil {
+		return fmt.Errorf("resolve version of %s: %v", v.GetDirPath(), err)
+	}
+
+	if !v.loadVersionFromTags(tags) {
+		slog.Info(fmt.Sprintf("No tag found for project %s with format %s, starting from version %s",
+			v.GetDirPath(), v.GetTagFormat(), v.Version))
+	}
+	return nil
+}
+
+// loadVersionFromTags sets the version and commit of the latest tag of the project. While the latest tag is a
+// prerelease, the commit of the latest final release is kept as release commit. It returns false when no tag belongs
+// to the project.
+func (v *ConfigVersion) loadVersionFromTags(tags []git.Tag) bool {
+	var latest, latestRelease *semver.Version
+	var latestCommit, latestReleaseCommit string
+
+	for _, tag := range tags {
+		versionStr, ok := v.ParseGitTag(tag.Name)
+		if !ok {
+			continue
+		}
+		version, err := semver.NewVersion(versionStr)
+		if err != nil {
+			continue
+		}
+
+		if latest == nil || version.GreaterThan(latest) {
+			latest, latestCommit = version, tag.Commit
+		}
+		if len(version.Prerelease()) == 0 && (latestRelease == nil || version.GreaterThan(latestRelease)) {
+			latestRelease, latestReleaseCommit = version, tag.Commit
+		}
+	}
+
+	if latest == nil {
+		return false
+	}
+
+	if len(latest.Prerelease()) == 0 {
+		v.ReleaseCommit = ""
+	} else if latestRelease != nil {
+		v.ReleaseCommit = latestReleaseCommit
+	} else if len(v.ReleaseCommit) == 0 {
+		// No final release yet, the prereleases started from the commit of the config version file
+		v.ReleaseCommit = v.Commit
+	}
+	v.Version = latest.String()
+	v.Commit = latestCommit
+
+	return true
+}
+
+// Release is a version of the project and the git tag it was released with.
+type Release struct {
+	Version string
+	Tag     string
+	Commit  string
+	Date    time.Time
+}
+
+// GetReleases returns the releases of the project found in tags, oldest version first.
+func (v *ConfigVersion) GetReleases(tags []git.Tag) []Release {
+	releases := make([]Release, 0)
+	versions := make(map[string]*semver.Version)
+	for _, tag := range tags {
+		versionStr, ok := v.ParseGitTag(tag.Name)
+		if !ok {
+			continue
+		}
+		version, err := semver.NewVersion(versionStr)
+		if err != nil {
+			continue
+		}
+		versions[tag.Name] = version
+		releases = append(releases, Release{Version: version.String(), Tag: tag.Name, Commit: tag.Commit, Date: tag.Date})
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return versions[releases[i].Tag].LessThan(versions[releases[j].Tag])
+	})
+	return releases
+}
+
+// GetReleasesFromBumps returns the releases of the project found in the history of its config version file, oldest
+// version first. Each commit that changed the version of the file released it, which is how projects without release
+// tags are followed.
+func (v *ConfigVersion) GetReleasesFromBumps(repo git.Repository) ([]Release, error) {
+	commits, err := repo.GetCommits("", v.GetFilePath())
+	if err != nil {
+		return nil, fmt.Errorf("read history of %s: %v", v.GetFilePath(), err)
+	}
+
+	releases := make([]Release, 0)
+	previous := ""
+	for i := len(commits) - 1; i >= 0; i-- {
+		commit := commits[i]
+		data, err := repo.GetFileAt(commit.Hash, v.GetFilePath())
+		if err != nil {
+			return nil, fmt.Errorf("read %s at %s: %v", v.GetFilePath(), commit.Hash, err)
+		}
+
+		var file struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			slog.Debug(fmt.Sprintf("skipping %s at %s: %v", v.GetFilePath(), commit.Hash, err))
+			continue
+		}
+		if len(file.Version) == 0 || file.Version == previous {
+			continue
+		}
+		// The version the file was created with was not released by that commit
+		if len(previous) > 0 {
+			releases = append(releases, Release{
+				Version: file.Version,
+				Tag:     v.GetGitTagForVersion(file.Version),
+				Commit:  commit.Hash,
+				Date:    commit.Date,
+			})
+		}
+		previous = file.Version
+	}
+	return releases, nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	TagFormatVersion = "{version}"
+	TagFormatAlias   = "{alias}"
+	TagFormatPath    = "{path}"
+
+	// LegacyTagFormat is the format tags had before tag_format existed, it abuses the semver build metadata.
+	LegacyTagFormat = TagFormatVersion + "+" + TagFormatAlias
+)
+
+const versionPattern = `[0-9]+\.[0-9]+\.[0-9]+(?:-[0-9A-Za-z.-]+)?`
+
+// TagFormat is a git tag template. {version} is replaced by the version, {alias} by the alias of the project and
+// {path} by the directory of the project relative to the repository root, for example "{path}/v{version}" or
+// "{alias}-v{version}".
+type TagFormat string
+
+func (f TagFormat) Validate() error {
+	if !strings.Contains(string(f), TagFormatVersion) {
+		return fmt.Errorf("tag format %q must contain %s", f, TagFormatVersion)
+	}
+	return nil
+}
+
+// Format returns the tag of a version.
+func (f TagFormat) Format(version string, alias string, path string) string {
+	tag := f.withPath(path)
+	tag = strings.ReplaceAll(tag, TagFormatAlias, alias)
+	return strings.ReplaceAll(tag, TagFormatVersion, version)
+}
+
+// Parse returns the version of a tag that follows the format, and false when the tag does not follow it.
+func (f TagFormat) Parse(tag string, alias string, path string) (string, bool) {
+	pattern := regexp.QuoteMeta(f.withPath(path))
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta(TagFormatAlias), regexp.QuoteMeta(alias))
+	pattern = strings.Replace(pattern, regexp.QuoteMeta(TagFormatVersion), "("+versionPattern+")", 1)
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta(TagFormatVersion), versionPattern)
+
+	match := regexp.MustCompile("^" + pattern + "$").FindStringSubmatch(tag)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// withPath replaces {path}. A project at the repository root has no path, so the separator next to it is dropped
+// too ("{path}/v{version}" gives "v1.0.0").
+func (f TagFormat) withPath(path string) string {
+	format := string(f)
+	if path == "." || path == "" {
+		format = strings.ReplaceAll(format, TagFormatPath+"/", "")
+		format = strings.ReplaceAll(format, TagFormatPath+"-", "")
+		return strings.ReplaceAll(format, TagFormatPath, "")
+	}
+	return strings.ReplaceAll(format, TagFormatPath, path)
+}
+
+// findRepositoryRoot walks up from dirPath to the first directory with a .git entry.
+func findRepositoryRoot(dirPath string) (string, bool) {
+	current := dirPath
+	for {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current, true
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", false
+		}
+		current = parent
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTagFormat(t *testing.T) {
+	tests := []struct {
+		format  TagFormat
+		path    string
+		version string
+		tag     string
+	}{
+		{LegacyTagFormat, "charts/app", "1.2.3", "1.2.3+app"},
+		{"{path}/v{version}", "modules/client", "1.2.3-rc.0", "modules/client/v1.2.3-rc.0"},
+		{"{path}/v{version}", ".", "1.2.3", "v1.2.3"},
+		{"{alias}-v{version}", "charts/app", "1.2.3", "app-v1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			if got := tt.format.Format(tt.version, "app", tt.path); got != tt.tag {
+				t.Errorf("Format() = %s, want %s", got, tt.tag)
+			}
+			got, ok := tt.format.Parse(tt.tag, "app", tt.path)
+			if !ok || got != tt.version {
+				t.Errorf("Parse(%s) = %s, %t, want %s", tt.tag, got, ok, tt.version)
+			}
+		})
+	}
+
+	if _, ok := TagFormat("{alias}-v{version}").Parse("other-v1.2.3", "app", "."); ok {
+		t.Errorf("expected a tag of another alias not to be parsed")
+	}
+	if err := TagFormat("{alias}").Validate(); err == nil {
+		t.Errorf("expected a format without {version} to be invalid")
+	}
+}
+
+func TestGetRelativeDirPath(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	dirPath := filepath.Join(root, "modules", "client")
+
+	v := NewConfigVersion(dirPath, "1.0.0", "abc123", "client")
+	v.TagFormat = "{path}/v{version}"
+	if got := v.GetGitTag(); got != "modules/client/v1.0.0" {
+		t.Errorf("GetGitTag() = %s, want modules/client/v1.0.0", got)
+	}
+}
@@ -1,34 +1,52 @@
 package config
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"reflect"
 	"regexp"
 	"strings"
+
+	"github.com/Masterminds/semver"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/atomicfile"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/changelog"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
 )
 
 type ConfigVersion struct {
 	dirPath string
 
-	Version               string    `json:"version" yaml:"version" plain:"version"`
-	Commit                string    `json:"commit" yaml:"commit" plain:"commit"`
-	VersionFiles          []string  `json:"version_files" yaml:"version_files" plain:"version_files"`
-	Alias                 string    `json:"alias" yaml:"alias" plain:"alias"`
-	Hooks                 HookTypes `json:"hooks,omitempty" yaml:"hooks,omitempty" plain:"hooks,omitempty"`
-	UpdateChangelogOnBump bool      `json:"update_changelog_on_bump,omitempty" yaml:"update_changelog_on_bump,omitempty" plain:"update_changelog_on_bump,omitempty"`
-}
-
-type HookTypes struct {
-	PreBump       string `json:"pre_bump,omitempty" yaml:"pre_bump,omitempty" plain:"pre_bump,omitempty"`
-	PostBump      string `json:"post_bump,omitempty" yaml:"post_bump,omitempty" plain:"post_bump,omitempty"`
-	PreChangelog  string `json:"pre_changelog,omitempty" yaml:"pre_changelog,omitempty" plain:"pre_changelog,omitempty"`
-	PostChangelog string `json:"post_changelog,omitempty" yaml:"post_changelog,omitempty" plain:"post_changelog,omitempty"`
+	// Set when a repository config is found: the repository config, the keys of the config version file and the
+	// keys inherited from the repository config.
+	repositoryConfig *RepositoryConfig
+	present          map[string]json.RawMessage
+	inherited        map[string]bool
+
+	hooksDisabled bool
+
+	// Set by FindConfigVersions: the directories of the discovered projects nested in this one
+	nestedProjects []string
+
+	Version               string     `json:"version" yaml:"version" plain:"version"`
+	Commit                string     `json:"commit" yaml:"commit" plain:"commit"`
+	ReleaseCommit         string     `json:"release_commit,omitempty" yaml:"release_commit,omitempty" plain:"release_commit,omitempty"`
+	VersionFiles          []string   `json:"version_files" yaml:"version_files" plain:"version_files"`
+	Alias                 string     `json:"alias" yaml:"alias" plain:"alias"`
+	TagFormat             TagFormat  `json:"tag_format,omitempty" yaml:"tag_format,omitempty" plain:"tag_format,omitempty"`
+	VersionSource         string     `json:"version_source,omitempty" yaml:"version_source,omitempty" plain:"version_source,omitempty"`
+	Hooks                 *HookTypes `json:"hooks,omitempty" yaml:"hooks,omitempty" plain:"hooks,omitempty"`
+	HookTimeout           string     `json:"hook_timeout,omitempty" yaml:"hook_timeout,omitempty" plain:"hook_timeout,omitempty"`
+	UpdateChangelogOnBump bool       `json:"update_changelog_on_bump,omitempty" yaml:"update_changelog_on_bump,omitempty" plain:"update_changelog_on_bump,omitempty"`
+	ChangelogTemplate     string     `json:"changelog_template,omitempty" yaml:"changelog_template,omitempty" plain:"changelog_template,omitempty"`
+	ChangelogFormats      []string   `json:"changelog_formats,omitempty" yaml:"changelog_formats,omitempty" plain:"changelog_formats,omitempty"`
+	IncludeNestedProjects bool       `json:"include_nested_projects,omitempty" yaml:"include_nested_projects,omitempty" plain:"include_nested_projects,omitempty"`
+	Paths                 []string   `json:"paths,omitempty" yaml:"paths,omitempty" plain:"paths,omitempty"`
+	IgnorePaths           []string   `json:"ignore_paths,omitempty" yaml:"ignore_paths,omitempty" plain:"ignore_paths,omitempty"`
+
+	ChangeTypes conventionalcommits.ChangeTypes `json:"change_types,omitempty" yaml:"change_types,omitempty" plain:"change_types,omitempty"`
 }
 
 func NewConfigVersion(dirPath string, version string, commit string, alias string) *ConfigVersion {
@@ -62,25 +80,72 @@ func ReadConfigVersion(configVersionPath string) (*ConfigVersion, error) {
 	}
 
 	version.dirPath = filepath.Dir(configVersionPath)
+
+	repositoryConfig, err := FindRepositoryConfig(version.dirPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(repositoryConfig.GetFilePath()) > 0 {
+		var present map[string]json.RawMessage
+		if err := json.Unmarshal(data, &present); err != nil {
+			return nil, fmt.Errorf("unmarshal json: %v", err)
+		}
+		version.present = present
+		if err := repositoryConfig.inherit(&version, present); err != nil {
+			return nil, fmt.Errorf("config version %s: %v", configVersionPath, err)
+		}
+	}
+
+	if len(version.TagFormat) > 0 {
+		if err := version.TagFormat.Validate(); err != nil {
+			return nil, fmt.Errorf("config version %s: %v", configVersionPath, err)
+		}
+	}
+	if err := validateVersionSource(version.VersionSource); err != nil {
+		return nil, fmt.Errorf("config version %s: %v", configVersionPath, err)
+	}
+	if err := validateHookTimeout(version.HookTimeout); err != nil {
+		return nil, fmt.Errorf("config version %s: %v", configVersionPath, err)
+	}
+	if err := version.ChangeTypes.Validate(); err != nil {
+		return nil, fmt.Errorf("config version %s: %v", configVersionPath, err)
+	}
+	if err := changelog.ValidateFormats(version.ChangelogFormats); err != nil {
+		return nil, fmt.Errorf("config version %s: %v", configVersionPath, err)
+	}
+	if err := validatePaths(version.Paths, version.IgnorePaths); err != nil {
+		return nil, fmt.Errorf("config version %s: %v", configVersionPath, err)
+	}
+
 	return &version, nil
 }
 
 func (v *ConfigVersion) Save() error {
-	data, err := json.MarshalIndent(v, "", "  ")
+	data, err := v.marshal()
 	if err != nil {
-		return fmt.Errorf("parse struct to json: %v", err)
+		return err
 	}
 
 	slog.Debug(fmt.Sprintf("saving config version in %s with data:\n%s", v.GetFilePath(), string(data)))
 
-	err = os.WriteFile(v.GetFilePath(), data, 0644)
+	err = atomicfile.Write(v.GetFilePath(), data)
 	if err != nil {
-		return fmt.Errorf("write file %s: %v", v.GetFilePath(), err)
+		return err
 	}
 
 	return nil
 }
 
+// marshal returns the content of the config version file. Values inherited from the repository config are left out.
+func (v *ConfigVersion) marshal() ([]byte, error) {
+	own := v.withoutInherited()
+	data, err := json.MarshalIndent(&own, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("parse struct to json: %v", err)
+	}
+	return data, nil
+}
+
 func (v *ConfigVersion) GetFilePath() string {
 	return filepath.Join(v.dirPath, defaultFileName)
 }
@@ -90,68 +155,152 @@ func (v *ConfigVersion) GetDirPath() string {
 }
 
 func (v *ConfigVersion) GetGitTag() string {
-	if len(v.Alias) > 0 {
-		return v.Version + "+" + v.Alias
-	}
-	return v.Version
+	return v.GetGitTagForVersion(v.Version)
 }
 
-func (v *ConfigVersion) RunPreBump() error {
-	return v.runHook("PreBump")
+// GetGitTagForVersion returns the git tag the project would have at the given version.
+func (v *ConfigVersion) GetGitTagForVersion(version string) string {
+	return v.GetTagFormat().Format(version, v.Alias, v.GetRelativeDirPath())
 }
 
-func (v *ConfigVersion) RunPostBump() error {
-	return v.runHook("PostBump")
+// ParseGitTag returns the version of a tag of the project, and false when the tag does not belong to it.
+func (v *ConfigVersion) ParseGitTag(tag string) (string, bool) {
+	return v.GetTagFormat().Parse(tag, v.Alias, v.GetRelativeDirPath())
 }
 
-func (v *ConfigVersion) RunPreChangelog() error {
-	return v.runHook("PreChangelog")
+// GetChangeTypes returns the change types of the project, the default ones when none are configured.
+func (v *ConfigVersion) GetChangeTypes() conventionalcommits.ChangeTypes {
+	if len(v.ChangeTypes) > 0 {
+		return v.ChangeTypes
+	}
+	return conventionalcommits.DefaultChangeTypes()
 }
 
-func (v *ConfigVersion) RunPostChangelog() error {
-	return v.runHook("PostChangelog")
+// GetChangelogTemplatePath returns the path of the changelog template of the project, empty for the built-in one. A
+// relative changelog_template is relative to the file that sets it, the config version file or the repository config.
+func (v *ConfigVersion) GetChangelogTemplatePath() string {
+	if len(v.ChangelogTemplate) == 0 || filepath.IsAbs(v.ChangelogTemplate) {
+		return v.ChangelogTemplate
+	}
+	if v.inherited["changelog_template"] {
+		return filepath.Join(filepath.Dir(v.repositoryConfig.GetFilePath()), v.ChangelogTemplate)
+	}
+	return filepath.Join(v.dirPath, v.ChangelogTemplate)
 }
 
-func (v *ConfigVersion) runHook(hookName string) error {
-	hookValue := reflect.ValueOf(v.Hooks).FieldByName(hookName)
-	if !hookValue.IsValid() {
-		slog.Debug(fmt.Sprintf("hook %s not found", hookName))
-		return nil
+// GetChangelogFormats returns the formats the changelog of the project is written in, Markdown when none are
+// configured.
+func (v *ConfigVersion) GetChangelogFormats() []string {
+	if len(v.ChangelogFormats) > 0 {
+		return v.ChangelogFormats
 	}
-	hook := hookValue.String()
-	if len(hook) == 0 {
-		slog.Debug(fmt.Sprintf("hook %s is empty", hookName))
-		return nil
+	return []string{changelog.FormatMarkdown}
+}
+
+// GetChangelogLinks returns the links to the forge set in the repository config, none without repository config.
+func (v *ConfigVersion) GetChangelogLinks() changelog.Links {
+	if v.repositoryConfig == nil {
+		return changelog.Links{}
 	}
+	return v.repositoryConfig.GetChangelogLinks()
+}
 
-	slog.Debug(fmt.Sprintf("running hook %s: %s", hookName, hook))
+// GetTagFormat returns the tag format of the project. Without tag_format, tags are "{version}+{alias}", or just
+// "{version}" for projects without alias.
+func (v *ConfigVersion) GetTagFormat() TagFormat {
+	if len(v.TagFormat) > 0 {
+		return v.TagFormat
+	}
+	if len(v.Alias) > 0 {
+		return LegacyTagFormat
+	}
+	return TagFormatVersion
+}
 
-	output, err := exec.Command("bash", "-c", hook).CombinedOutput()
+// GetRelativeDirPath returns the directory of the project relative to the root of its git repository, with forward
+// slashes. Outside a repository it is the name of the directory.
+func (v *ConfigVersion) GetRelativeDirPath() string {
+	if relPath, ok := relativeToRepository(v.dirPath); ok {
+		return relPath
+	}
+	absDirPath, err := filepath.Abs(v.dirPath)
 	if err != nil {
-		return fmt.Errorf("run hook %s: %v", hookName, err)
+		return filepath.Base(v.dirPath)
 	}
+	return filepath.Base(absDirPath)
+}
 
-	// TODO: Pretty log info with colors
-	if len(output) > 0 {
-		slog.Info(fmt.Sprintf("\n\033[32mHook %s output:\n%s\033[0m", hookName, string(output)))
-	} else {
-		slog.Info(fmt.Sprintf("\n\033[32mLaunch hook %s\n\033[0m", hookName))
+// relativeToRepository returns filePath relative to the root of its git repository, with forward slashes, and false
+// outside a repository.
+func relativeToRepository(filePath string) (string, bool) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", false
+	}
+	root, ok := findRepositoryRoot(absPath)
+	if !ok {
+		return "", false
+	}
+	relPath, err := filepath.Rel(root, absPath)
+	if err != nil {
+		return "", false
 	}
+	return filepath.ToSlash(relPath), true
+}
 
-	return nil
+// repositoryPath returns filePath as it is shown to the user: relative to the root of its git repository, or as it is
+// outside a repository, with forward slashes.
+func repositoryPath(filePath string) string {
+	if relPath, ok := relativeToRepository(filePath); ok {
+		return relPath
+	}
+	return filepath.ToSlash(filepath.Clean(filePath))
 }
 
-func (v *ConfigVersion) UpdateVersion(newVersion string, lastCommit string) ([]string, error) {
-	modifiedFiles := make([]string, 0)
+// IsPrerelease tells whether the current version is a prerelease (1.3.0-rc.0).
+func (v *ConfigVersion) IsPrerelease() bool {
+	return isPrerelease(v.Version)
+}
 
-	v.Version = newVersion
-	v.Commit = lastCommit
-	err := v.Save()
-	if err != nil {
-		return nil, err
+// GetStartCommit returns the commit from which the next bump reads the history. While the project is in prerelease,
+// a final release reads from the last final release, so every prerelease change is folded into it.
+func (v *ConfigVersion) GetStartCommit(prerelease string) string {
+	if v.IsPrerelease() && len(prerelease) == 0 && len(v.ReleaseCommit) > 0 {
+		return v.ReleaseCommit
 	}
-	modifiedFiles = append(modifiedFiles, v.GetFilePath())
+	return v.Commit
+}
+
+// FileChange is the content a bump gives to a file, next to the content it had before.
+type FileChange struct {
+	FilePath string
+	Before   []byte
+	After    []byte
+	// Matches is the number of versions replaced in a version file
+	Matches int
+}
+
+// PlanVersion computes the changes a bump to newVersion makes on the config version file and on the version files,
+// without writing anything.
+func (v *ConfigVersion) PlanVersion(newVersion string, lastCommit string) ([]FileChange, error) {
+	changes := make([]FileChange, 0)
 
+	// With the version in the tags, the config version file only holds settings and is left as it is
+	if !v.IsVersionFromTags() {
+		next := v.bumped(newVersion, lastCommit)
+		before, err := readFileIfExists(v.GetFilePath())
+		if err != nil {
+			return nil, err
+		}
+		after, err := next.rewrite(before)
+		if err != nil {
+			return nil, fmt.Errorf("update %s: %v", v.GetFilePath(), err)
+		}
+		changes = append(changes, FileChange{FilePath: v.GetFilePath(), Before: before, After: after})
+	}
+
+	// Several entries may target the same file, each one applies on the result of the previous one
+	pending := make(map[string]int)
 	for _, versionFile := range v.VersionFiles {
 		index := strings.Index(versionFile, ":")
 		if index == -1 {
@@ -163,66 +312,108 @@ func (v *ConfigVersion) UpdateVersion(newVersion string, lastCommit string) ([]s
 		substring := versionFile[index+1:]
 		filePath := filepath.Join(v.dirPath, fileName)
 
-		err := updateVersionOfFiles(filePath, substring, newVersion)
+		i, ok := pending[filePath]
+		if !ok {
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, FileChange{FilePath: filePath, Before: content, After: content})
+			i = len(changes) - 1
+			pending[filePath] = i
+		}
+
+		var content []byte
+		var err error
+		matches := 1
+		if path, ok := parseKeyPath(fileName, substring); ok {
+			content, err = replaceKeyPath(changes[i].After, fileName, path, newVersion)
+		} else {
+			content, matches, err = replaceVersion(changes[i].After, substring, newVersion)
+		}
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("update %s: %v", filePath, err)
 		}
-		modifiedFiles = append(modifiedFiles, filePath)
+		if matches == 0 {
+			return nil, fmt.Errorf("update %s: `%s` matches no version", filePath, substring)
+		}
+		changes[i].After = content
+		changes[i].Matches += matches
 	}
 
-	return modifiedFiles, nil
+	return changes, nil
 }
 
-func updateVersionOfFiles(filePath, substring, newVersion string) error {
-	file, err := os.OpenFile(filePath, os.O_RDWR, 0644)
+func (v *ConfigVersion) UpdateVersion(newVersion string, lastCommit string) ([]string, error) {
+	changes, err := v.PlanVersion(newVersion, lastCommit)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	var lines []string
+	modifiedFiles := make([]string, 0)
+	for _, change := range changes {
+		err := atomicfile.Write(change.FilePath, change.After)
+		if err != nil {
+			return nil, err
+		}
+		if change.Matches > 0 {
+			slog.Info(fmt.Sprintf("Replaced %d version(s) in %s", change.Matches, change.FilePath))
+		}
+		modifiedFiles = append(modifiedFiles, change.FilePath)
+	}
 
-	// Regular expression to find the version in the file
-	regularExpression := ""
-	validRegexp, err := isARegExp(substring)
-	// Check if the substring is a regular expression that compiles
-	if err != nil {
-		return err
+	*v = v.bumped(newVersion, lastCommit)
+
+	return modifiedFiles, nil
+}
+
+// rewrite returns content, the config version file, with the version, commit and release_commit of the config version.
+// Only the bytes of those values change, so formatting, key order and line endings are kept. A missing file is
+// written in full.
+func (v *ConfigVersion) rewrite(content []byte) ([]byte, error) {
+	if len(content) == 0 {
+		return v.marshal()
 	}
-	if validRegexp { // If it is a regular expression, use it as is
-		regularExpression = substring
-	} else { // If it is a literal string, use it as a word boundary
-		regularExpression = fmt.Sprintf(`(?i)\b%s\b\s*[:=]\s*([0-9]+\.[0-9]+\.[0-9]+)`, substring)
+
+	content, err := setJSONString(content, "version", v.Version, "", false)
+	if err != nil {
+		return nil, err
 	}
-	versionRegex, err := regexp.Compile(regularExpression)
+	content, err = setJSONString(content, "commit", v.Commit, "version", false)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return setJSONString(content, "release_commit", v.ReleaseCommit, "commit", true)
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		match := versionRegex.FindStringSubmatch(line)
-		if len(match) > 1 {
-			// The line contains the substring given by 'substring'
-			oldVersion := strings.TrimSpace(match[1])
-			// Replace the old version with the new version
-			newLine := strings.Replace(line, oldVersion, newVersion, 1)
-			lines = append(lines, newLine)
-		} else {
-			// The line does not contain the substring given by 'substring'
-			lines = append(lines, line)
-		}
+// bumped returns a copy of the config version moved to newVersion at lastCommit.
+func (v *ConfigVersion) bumped(newVersion string, lastCommit string) ConfigVersion {
+	next := *v
+	// Remember the last final release while the project is in prerelease
+	if !isPrerelease(newVersion) {
+		next.ReleaseCommit = ""
+	} else if !v.IsPrerelease() {
+		next.ReleaseCommit = v.Commit
 	}
+	next.Version = newVersion
+	next.Commit = lastCommit
+	return next
+}
 
-	// Write the updated lines back to the file
-	file.Truncate(0)
-	file.Seek(0, 0)
-	for _, line := range lines {
-		file.WriteString(line + "\n")
+func readFileIfExists(filePath string) ([]byte, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read file %s: %v", filePath, err)
 	}
+	return data, nil
+}
 
-	return nil
+func isPrerelease(version string) bool {
+	parsed, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return len(parsed.Prerelease()) > 0
 }
 
 func isARegExp(s string) (bool, error) {
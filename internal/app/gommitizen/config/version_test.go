@@ -53,7 +53,7 @@ func TestRead(t *testing.T) {
 	}
 
 	// Leer el archivo JSON usando la función Read
-	v, err := ReadConfigVersion(tempDir)
+	v, err := ReadConfigVersion(filePath)
 	if err != nil {
 		t.Fatalf("Read() error = %v", err)
 	}
@@ -70,3 +70,57 @@ func TestRead(t *testing.T) {
 		t.Errorf("Read() = %v, want %v", v, expected)
 	}
 }
+
+func TestUpdateVersionPrerelease(t *testing.T) {
+	tempDir := t.TempDir()
+	chartPath := filepath.Join(tempDir, "Chart.yaml")
+	err := os.WriteFile(chartPath, []byte("name: chart\nversion: 1.3.0-rc.0\n"), 0644)
+	if err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	v := NewConfigVersion(tempDir, "1.3.0-rc.0", "abc123", "chart")
+	v.VersionFiles = []string{"Chart.yaml:version"}
+	if _, err := v.UpdateVersion("1.3.0-rc.1", "def456"); err != nil {
+		t.Fatalf("UpdateVersion() error = %v", err)
+	}
+
+	data, err := os.ReadFile(chartPath)
+	if err != nil {
+		t.Fatalf("failed to read temp file: %v", err)
+	}
+	if string(data) != "name: chart\nversion: 1.3.0-rc.1\n" {
+		t.Errorf("unexpected Chart.yaml content:\n%s", string(data))
+	}
+}
+
+func TestPlanVersionKeepsConfigVersionLayout(t *testing.T) {
+	tempDir := t.TempDir()
+	content := "{\"alias\":\"api\",\"version\":\"1.2.3\",\r\n\"commit\":\"abc123\",\"version_files\":[]}"
+	writeTestFile(t, filepath.Join(tempDir, defaultFileName), content)
+	v, err := ReadConfigVersion(filepath.Join(tempDir, defaultFileName))
+	if err != nil {
+		t.Fatalf("ReadConfigVersion() error = %v", err)
+	}
+
+	steps := []struct {
+		version  string
+		commit   string
+		expected string
+	}{
+		{"1.3.0-rc.0", "def456", "{\"alias\":\"api\",\"version\":\"1.3.0-rc.0\",\r\n\"commit\":\"def456\",\r\n\"release_commit\":\"abc123\",\"version_files\":[]}"},
+		{"1.3.0", "fed789", "{\"alias\":\"api\",\"version\":\"1.3.0\",\r\n\"commit\":\"fed789\",\"version_files\":[]}"},
+	}
+	for _, step := range steps {
+		changes, err := v.PlanVersion(step.version, step.commit)
+		if err != nil {
+			t.Fatalf("PlanVersion() error = %v", err)
+		}
+		if len(changes) != 1 || string(changes[0].After) != step.expected {
+			t.Fatalf("expected only the values to change:\n%q\ngot:\n%q", step.expected, changes[0].After)
+		}
+		if _, err := v.UpdateVersion(step.version, step.commit); err != nil {
+			t.Fatalf("UpdateVersion() error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package conventionalcommits
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	IncrementMajor = "major"
+	IncrementMinor = "minor"
+	IncrementPatch = "patch"
+	IncrementNone  = "none"
+)
+
+var validIncrements = []string{IncrementMajor, IncrementMinor, IncrementPatch, IncrementNone}
+
+// ChangeType groups the commit types with the same meaning: the changelog section they are listed in, where that
+// section goes and the increment of the version they cause. Commits of hidden types are left out of the changelog,
+// unless they are breaking changes. ShowType prefixes each changelog entry with its commit type, for sections
+// gathering unrelated types.
+type ChangeType struct {
+	Order      int      `json:"order,omitempty" yaml:"order,omitempty"`
+	CommonName string   `json:"section" yaml:"section"`
+	Prefixes   []string `json:"prefixes" yaml:"prefixes"`
+	Increment  string   `json:"increment" yaml:"increment"`
+	Hidden     bool     `json:"hidden,omitempty" yaml:"hidden,omitempty"`
+	ShowType   bool     `json:"show_type,omitempty" yaml:"show_type,omitempty"`
+}
+
+// ChangeTypes is the list of the commit types gommitizen takes into account. Commits of any other type are ignored.
+type ChangeTypes []ChangeType
+
+// DefaultChangeTypes returns the change types used when none are configured.
+func DefaultChangeTypes() ChangeTypes {
+	return ChangeTypes{
+		{
+			Order:      0,
+			CommonName: CommonNameBC,
+			Prefixes:   []string{"bc", "breaking change"},
+			Increment:  IncrementMajor,
+			// Listed in the breaking changes section
+			Hidden: true,
+		},
+		{
+			Order:      1,
+			CommonName: CommonNameFeat,
+			Prefixes:   []string{"feat", "feature"},
+			Increment:  IncrementMinor,
+		},
+		{
+			Order:      2,
+			CommonName: CommonNameFix,
+			Prefixes:   []string{"fix", "bug", "bugfix"},
+			Increment:  IncrementPatch,
+		},
+		{
+			Order:      3,
+			CommonName: CommonNameRefactor,
+			Prefixes:   []string{"refactor"},
+			Increment:  IncrementPatch,
+		},
+		{
+			Order:      4,
+			CommonName: CommonNameMiscellaneous,
+			Prefixes:   []string{"perf", "performance", "test", "tests", "chore", "ci", "build", "docs", "style"},
+			Increment:  IncrementNone,
+			ShowType:   true,
+		},
+	}
+}
+
+func (cts ChangeTypes) Validate() error {
+	seen := make(map[string]string)
+	for _, ct := range cts {
+		if len(ct.CommonName) == 0 {
+			return fmt.Errorf("change type with prefixes %v has no section", ct.Prefixes)
+		}
+		if len(ct.Prefixes) == 0 {
+			return fmt.Errorf("change type %s has no prefixes", ct.CommonName)
+		}
+		valid := false
+		for _, increment := range validIncrements {
+			if ct.Increment == increment {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("change type %s: invalid increment '%s', supported values: %s",
+				ct.CommonName, ct.Increment, strings.Join(validIncrements, ", "))
+		}
+		for _, prefix := range ct.Prefixes {
+			prefix = strings.ToLower(prefix)
+			if other, ok := seen[prefix]; ok {
+				return fmt.Errorf("prefix '%s' is in change types %s and %s", prefix, other, ct.CommonName)
+			}
+			seen[prefix] = ct.CommonName
+		}
+	}
+	return nil
+}
+
+// Lookup returns the change type of a commit type, the comparison ignores case.
+func (cts ChangeTypes) Lookup(commitType string) (ChangeType, bool) {
+	for _, ct := range cts {
+		for _, prefix := range ct.Prefixes {
+			if strings.EqualFold(commitType, prefix) {
+				return ct, true
+			}
+		}
+	}
+	return ChangeType{}, false
+}
+
+// Sections returns the change types shown in the changelog, in section order.
+func (cts ChangeTypes) Sections() ChangeTypes {
+	sections := make(ChangeTypes, 0, len(cts))
+	for _, ct := range cts {
+		if !ct.Hidden {
+			sections = append(sections, ct)
+		}
+	}
+	sort.SliceStable(sections, func(i, j int) bool { return sections[i].Order < sections[j].Order })
+	return sections
+}
+
+func (cts ChangeTypes) incrementOf(commonName string) string {
+	for _, ct := range cts {
+		if ct.CommonName == commonName {
+			return ct.Increment
+		}
+	}
+	return IncrementNone
+}
@@ -0,0 +1,113 @@
+package conventionalcommits
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/leodido/go-conventionalcommits"
+	"github.com/leodido/go-conventionalcommits/parser"
+)
+
+// CheckRules are the optional rules a commit message must follow on top of being a conventional commit of a known
+// type.
+type CheckRules struct {
+	// Scopes allowed in the header, any scope is allowed when empty
+	Scopes []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	// MaxSubjectLength is the maximum length of the subject, the description after the type and scope, not checked when
+	// zero
+	MaxSubjectLength int `json:"max_subject_length,omitempty" yaml:"max_subject_length,omitempty"`
+	// IssuePattern is a regular expression that must match somewhere in the message, like `#[0-9]+` or `[A-Z]+-[0-9]+`
+	IssuePattern string `json:"issue_pattern,omitempty" yaml:"issue_pattern,omitempty"`
+}
+
+func (r CheckRules) Validate() error {
+	if len(r.IssuePattern) > 0 {
+		if _, err := regexp.Compile(r.IssuePattern); err != nil {
+			return fmt.Errorf("invalid issue pattern: %v", err)
+		}
+	}
+	if r.MaxSubjectLength < 0 {
+		return fmt.Errorf("invalid max subject length %d", r.MaxSubjectLength)
+	}
+	return nil
+}
+
+// ignoredMessagePrefixes are the messages git writes by itself, which are not checked.
+var ignoredMessagePrefixes = []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "}
+
+// Check returns the reasons why message is not a valid conventional commit, none when it is. The message is parsed
+// as bump parses it, so a message passing the check is taken into account by bump.
+func Check(message string, changeTypes ChangeTypes, rules CheckRules) []string {
+	message = strings.TrimSpace(message)
+	if len(message) == 0 {
+		return []string{"empty message"}
+	}
+	for _, prefix := range ignoredMessagePrefixes {
+		if strings.HasPrefix(message, prefix) {
+			return nil
+		}
+	}
+
+	res, err := parser.NewMachine(parserOptions()...).Parse([]byte(message))
+	if res == nil || !res.Ok() {
+		return []string{fmt.Sprintf("not a conventional commit: %v", err)}
+	}
+	ccData := res.(*conventionalcommits.ConventionalCommit)
+
+	reasons := make([]string, 0)
+	if err != nil {
+		reasons = append(reasons, fmt.Sprintf("malformed body or footers: %v", err))
+	}
+
+	if _, ok := changeTypes.Lookup(ccData.Type); !ok {
+		reasons = append(reasons, fmt.Sprintf("unknown type '%s', allowed types: %s", ccData.Type, strings.Join(changeTypes.prefixes(), ", ")))
+	}
+
+	if len(rules.Scopes) > 0 {
+		scope := ""
+		if ccData.Scope != nil {
+			scope = *ccData.Scope
+		}
+		if !ContainsFold(rules.Scopes, scope) {
+			if len(scope) == 0 {
+				reasons = append(reasons, fmt.Sprintf("missing scope, allowed scopes: %s", strings.Join(rules.Scopes, ", ")))
+			} else {
+				reasons = append(reasons, fmt.Sprintf("unknown scope '%s', allowed scopes: %s", scope, strings.Join(rules.Scopes, ", ")))
+			}
+		}
+	}
+
+	if subjectLength := len([]rune(ccData.Description)); rules.MaxSubjectLength > 0 && subjectLength > rules.MaxSubjectLength {
+		reasons = append(reasons, fmt.Sprintf("subject is %d characters long, the maximum is %d", subjectLength, rules.MaxSubjectLength))
+	}
+
+	if len(rules.IssuePattern) > 0 {
+		issueRegex, err := regexp.Compile(rules.IssuePattern)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("invalid issue pattern: %v", err))
+		} else if !issueRegex.MatchString(message) {
+			reasons = append(reasons, fmt.Sprintf("no issue reference matching `%s`", rules.IssuePattern))
+		}
+	}
+
+	return reasons
+}
+
+func (cts ChangeTypes) prefixes() []string {
+	prefixes := make([]string, 0)
+	for _, ct := range cts {
+		prefixes = append(prefixes, ct.Prefixes...)
+	}
+	return prefixes
+}
+
+// ContainsFold tells whether values holds value, ignoring case.
+func ContainsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
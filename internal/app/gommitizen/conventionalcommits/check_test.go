@@ -0,0 +1,57 @@
+package conventionalcommits
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	rules := CheckRules{
+		Scopes:           []string{"api", "cli"},
+		MaxSubjectLength: 40,
+		IssuePattern:     `#[0-9]+`,
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		rules   CheckRules
+		reasons []string
+	}{
+		{"valid", "feat(api): add endpoint\n\nRefs #12", rules, nil},
+		{"merge commit", "Merge branch 'main' into feature", rules, nil},
+		{"not conventional", "add endpoint", CheckRules{}, []string{"not a conventional commit"}},
+		{"unknown type", "feature-request: add endpoint", CheckRules{}, []string{"unknown type 'feature-request'"}},
+		{"unknown scope", "fix(web): typo #1", rules, []string{"unknown scope 'web'"}},
+		{"missing scope", "fix: typo #1", rules, []string{"missing scope"}},
+		{"long subject", "fix(api): a very long description of the fix, far too long #1", rules, []string{"subject is 51 characters long"}},
+		{"long header", "fix(api): a very long description of the fix #1", rules, nil},
+		{"missing issue", "fix(api): typo", rules, []string{"no issue reference"}},
+		{"empty", "\n\n", CheckRules{}, []string{"empty message"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons := Check(tt.message, DefaultChangeTypes(), tt.rules)
+			if len(reasons) != len(tt.reasons) {
+				t.Fatalf("expected %d reasons, got %v", len(tt.reasons), reasons)
+			}
+			for i, reason := range tt.reasons {
+				if !strings.HasPrefix(reasons[i], reason) {
+					t.Errorf("expected reason starting with %q, got %q", reason, reasons[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckCustomChangeTypes(t *testing.T) {
+	changeTypes := ChangeTypes{{CommonName: "Dependencies", Prefixes: []string{"deps"}, Increment: IncrementPatch}}
+
+	if reasons := Check("deps: bump yaml", changeTypes, CheckRules{}); len(reasons) != 0 {
+		t.Errorf("expected deps to be valid, got %v", reasons)
+	}
+	if reasons := Check("feat: new flag", changeTypes, CheckRules{}); len(reasons) != 1 {
+		t.Errorf("expected feat to be rejected, got %v", reasons)
+	}
+}
@@ -16,12 +16,6 @@ import (
 
 // https://www.conventionalcommits.org/en/v1.0.0/
 
-type ChangeType struct {
-	Order      int
-	CommonName string
-	Prefixes   []string
-}
-
 type CommitData struct {
 	ShortHash string
 	Hash      string
@@ -31,40 +25,22 @@ type CommitData struct {
 	ChangeType       string
 	Scope            string
 	Subject          string
+	Increment        string
+
+	Breaking            bool
+	BreakingDescription string
 }
 
 const (
 	CommonNameBC            = "Breaking changes"
 	CommonNameFeat          = "Features"
-	CommonNameFix           = "Bug Fixes"
+	CommonNameFix           = "Fixes"
 	CommonNameRefactor      = "Refactors"
 	CommonNameMiscellaneous = "Miscellaneous"
 )
 
-var (
-	changeTypes = []ChangeType{
-		{
-			CommonName: CommonNameBC,
-			Prefixes:   []string{"bc", "breaking change"},
-		},
-		{
-			CommonName: CommonNameFeat,
-			Prefixes:   []string{"feat", "feature"},
-		},
-		{
-			CommonName: CommonNameFix,
-			Prefixes:   []string{"fix", "bug", "bugfix"},
-		},
-		{
-			CommonName: CommonNameRefactor,
-			Prefixes:   []string{"refactor"},
-		},
-		{
-			CommonName: CommonNameMiscellaneous,
-			Prefixes:   []string{"perf", "performance", "test", "tests", "chore", "ci", "build", "docs", "style"},
-		},
-	}
-)
+// breakingChangeFooter is the key the parser gives to both `BREAKING CHANGE:` and `BREAKING-CHANGE:` footers.
+const breakingChangeFooter = "breaking-change"
 
 func (cc CommitData) String() string {
 	if cc.Scope == "" {
@@ -74,24 +50,40 @@ func (cc CommitData) String() string {
 	}
 }
 
-func ReadConventionalCommits(commits []git.Commit) []CommitData {
-	cvcommits := make([]CommitData, 0)
+// GetConventionalCommits reads the commits of the repository since fromCommit that touch the files matched by
+// pathspecs and keeps the conventional ones whose type is in changeTypes.
+func GetConventionalCommits(repo git.Repository, fromCommit string, pathspecs []string, changeTypes ChangeTypes) ([]CommitData, error) {
+	commits, err := repo.GetCommits(fromCommit, pathspecs...)
+	if err != nil {
+		return []CommitData{}, err
+	}
+	return ReadConventionalCommits(commits, changeTypes), nil
+}
 
-	opts := []conventionalcommits.MachineOption{
-		parser.WithTypes(conventionalcommits.TypesConventional),
-		parser.WithBestEffort(),
+// GetConventionalCommitsBetween returns the conventional commits after fromCommit up to toCommit that touch the files
+// matched by pathspecs. An empty toCommit stands for HEAD, and an empty fromCommit for the start of the history.
+func GetConventionalCommitsBetween(repo git.Repository, fromCommit string, toCommit string, pathspecs []string, changeTypes ChangeTypes) ([]CommitData, error) {
+	commits, err := repo.GetCommitsBetween(fromCommit, toCommit, pathspecs...)
+	if err != nil {
+		return []CommitData{}, err
 	}
+	return ReadConventionalCommits(commits, changeTypes), nil
+}
+
+func ReadConventionalCommits(commits []git.Commit, changeTypes ChangeTypes) []CommitData {
+	cvcommits := make([]CommitData, 0)
+
+	opts := parserOptions()
 
 	for _, commit := range commits {
-		res, err := parser.NewMachine(opts...).Parse([]byte(commit.Subject))
-		if err != nil || !res.Ok() {
+		ccData, ok := parseMessage(commit, opts)
+		if !ok {
 			slog.Debug(fmt.Sprintf("ignore commit, no cc by parser: %s", commit.Subject))
 			continue
 		}
-		ccData := res.(*conventionalcommits.ConventionalCommit)
 
-		commonChangeType := determinateCommonChangeType(ccData.Type)
-		if commonChangeType == "unknown" {
+		changeType, ok := changeTypes.Lookup(ccData.Type)
+		if !ok {
 			slog.Debug(fmt.Sprintf("ignore commit, no cc by common: %s", ccData.Type))
 			continue
 		}
@@ -106,10 +98,17 @@ func ReadConventionalCommits(commits []git.Commit) []CommitData {
 			Hash:      commit.Hash,
 			Date:      commit.Date,
 
-			CommonChangeType: commonChangeType,
+			CommonChangeType: changeType.CommonName,
 			ChangeType:       ccData.Type,
 			Scope:            scope,
 			Subject:          ccData.Description,
+			Increment:        changeType.Increment,
+		}
+
+		// A type that bumps the major version is a breaking change by itself
+		if ccData.IsBreakingChange() || changeType.Increment == IncrementMajor {
+			cc.Breaking = true
+			cc.BreakingDescription = breakingDescription(ccData)
 		}
 
 		slog.Debug(fmt.Sprintf("cccommit: %v", cc))
@@ -118,37 +117,80 @@ func ReadConventionalCommits(commits []git.Commit) []CommitData {
 	return cvcommits
 }
 
-func determinateCommonChangeType(changeType string) string {
-	for _, ct := range changeTypes {
-		for _, prefix := range ct.Prefixes {
-			if strings.ToLower(changeType) == prefix {
-				return ct.CommonName
-			}
+// parserOptions are the settings of the parser of commit messages. Any type is parsed, the change types decide which
+// ones are kept.
+func parserOptions() []conventionalcommits.MachineOption {
+	return []conventionalcommits.MachineOption{
+		parser.WithTypes(conventionalcommits.TypesFreeForm),
+		parser.WithBestEffort(),
+	}
+}
+
+// parseMessage parses the full commit message, so body and footers are taken into account. If the body or the
+// footers are not well-formed, the best effort result is kept as long as the header is valid; otherwise the subject
+// alone is parsed.
+func parseMessage(commit git.Commit, opts []conventionalcommits.MachineOption) (*conventionalcommits.ConventionalCommit, bool) {
+	message := commit.Message
+	if len(message) == 0 {
+		message = commit.Subject
+	}
+
+	res, err := parser.NewMachine(opts...).Parse([]byte(message))
+	if res != nil && res.Ok() {
+		if err != nil {
+			slog.Debug(fmt.Sprintf("partial parse of commit %s: %v", commit.Hash, err))
 		}
+		return res.(*conventionalcommits.ConventionalCommit), true
+	}
+
+	if message == commit.Subject {
+		return nil, false
+	}
+	res, err = parser.NewMachine(opts...).Parse([]byte(commit.Subject))
+	if err != nil || res == nil || !res.Ok() {
+		return nil, false
 	}
-	return "unknown"
+	return res.(*conventionalcommits.ConventionalCommit), true
 }
 
+// breakingDescription returns the text of the breaking change footers, or the description of the commit when the
+// breaking change is only marked with `!` or by its type.
+func breakingDescription(ccData *conventionalcommits.ConventionalCommit) string {
+	if notes, ok := ccData.Footers[breakingChangeFooter]; ok && len(notes) > 0 {
+		return strings.Join(notes, "\n")
+	}
+	return ccData.Description
+}
+
+// DetermineIncrementType returns the highest increment of the commits. Commits without increment get the one of their
+// common change type in the default change types.
 func DetermineIncrementType(commits []CommitData) string {
 	var hasMinor, hasPatch bool
 
 	for _, commit := range commits {
-		switch commit.CommonChangeType {
-		case CommonNameBC:
-			return "major"
-		case CommonNameFeat:
+		if commit.Breaking {
+			return IncrementMajor
+		}
+		increment := commit.Increment
+		if len(increment) == 0 {
+			increment = DefaultChangeTypes().incrementOf(commit.CommonChangeType)
+		}
+		switch increment {
+		case IncrementMajor:
+			return IncrementMajor
+		case IncrementMinor:
 			hasMinor = true
-		case CommonNameFix, CommonNameRefactor:
+		case IncrementPatch:
 			hasPatch = true
 		}
 	}
 
 	switch {
 	case hasMinor:
-		return "minor"
+		return IncrementMinor
 	case hasPatch:
-		return "patch"
+		return IncrementPatch
 	default:
-		return "none"
+		return IncrementNone
 	}
 }
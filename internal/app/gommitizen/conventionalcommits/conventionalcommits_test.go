@@ -0,0 +1,116 @@
+package conventionalcommits
+
+import (
+	"testing"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+func TestReadConventionalCommitsBreakingChanges(t *testing.T) {
+	commits := []git.Commit{
+		{Hash: "aaaaaaaaaa", Subject: "feat: new flag", Message: "feat: new flag\n\nSome body.\n\nBREAKING CHANGE: the old flag is gone"},
+		{Hash: "bbbbbbbbbb", Subject: "fix(api)!: drop v1", Message: "fix(api)!: drop v1"},
+		{Hash: "cccccccccc", Subject: "fix: typo", Message: "fix: typo\n\nRefs: #12"},
+		{Hash: "dddddddddd", Subject: "not conventional", Message: "not conventional"},
+	}
+
+	cvCommits := ReadConventionalCommits(commits, DefaultChangeTypes())
+	if len(cvCommits) != 3 {
+		t.Fatalf("expected 3 conventional commits, got %d", len(cvCommits))
+	}
+
+	if !cvCommits[0].Breaking || cvCommits[0].BreakingDescription != "the old flag is gone" {
+		t.Errorf("expected breaking footer to be detected, got %+v", cvCommits[0])
+	}
+	if !cvCommits[1].Breaking || cvCommits[1].BreakingDescription != "drop v1" || cvCommits[1].Scope != "api" {
+		t.Errorf("expected bang marker to be detected, got %+v", cvCommits[1])
+	}
+	if cvCommits[2].Breaking {
+		t.Errorf("expected no breaking change, got %+v", cvCommits[2])
+	}
+}
+
+func TestDetermineIncrementType(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []CommitData
+		want    string
+	}{
+		{"none", []CommitData{{CommonChangeType: CommonNameMiscellaneous}}, "none"},
+		{"patch", []CommitData{{CommonChangeType: CommonNameFix}}, "patch"},
+		{"minor", []CommitData{{CommonChangeType: CommonNameFix}, {CommonChangeType: CommonNameFeat}}, "minor"},
+		{"major by footer", []CommitData{{CommonChangeType: CommonNameFix, Breaking: true}}, "major"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetermineIncrementType(tt.commits); got != tt.want {
+				t.Errorf("DetermineIncrementType() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadConventionalCommitsCustomChangeTypes(t *testing.T) {
+	changeTypes := ChangeTypes{
+		{CommonName: "Features", Prefixes: []string{"feat"}, Increment: IncrementMinor},
+		{CommonName: "Dependencies", Prefixes: []string{"deps"}, Increment: IncrementPatch},
+		{CommonName: "Security", Prefixes: []string{"security"}, Increment: IncrementPatch},
+		{CommonName: "Performance", Prefixes: []string{"perf"}, Increment: IncrementPatch},
+		{CommonName: "Chores", Prefixes: []string{"chore"}, Increment: IncrementNone, Hidden: true},
+	}
+	commits := []git.Commit{
+		{Hash: "aaaaaaaaaa", Subject: "deps: bump yaml", Message: "deps: bump yaml"},
+		{Hash: "bbbbbbbbbb", Subject: "perf: cache tags", Message: "perf: cache tags"},
+		{Hash: "cccccccccc", Subject: "refactor: split config", Message: "refactor: split config"},
+		{Hash: "dddddddddd", Subject: "chore: tidy", Message: "chore: tidy"},
+	}
+
+	cvCommits := ReadConventionalCommits(commits, changeTypes)
+	if len(cvCommits) != 3 {
+		t.Fatalf("expected 3 conventional commits, got %d", len(cvCommits))
+	}
+	if cvCommits[0].CommonChangeType != "Dependencies" || cvCommits[0].Increment != IncrementPatch {
+		t.Errorf("expected deps commit in Dependencies, got %+v", cvCommits[0])
+	}
+	if got := DetermineIncrementType(cvCommits[1:2]); got != IncrementPatch {
+		t.Errorf("expected perf to bump patch, got %s", got)
+	}
+	if got := DetermineIncrementType(cvCommits[2:]); got != IncrementNone {
+		t.Errorf("expected chore to bump nothing, got %s", got)
+	}
+}
+
+func TestChangeTypesValidate(t *testing.T) {
+	if err := DefaultChangeTypes().Validate(); err != nil {
+		t.Errorf("expected default change types to be valid, got %v", err)
+	}
+
+	invalid := []ChangeTypes{
+		{{CommonName: "Features", Prefixes: []string{"feat"}, Increment: "huge"}},
+		{{CommonName: "Features", Increment: IncrementMinor}},
+		{{Prefixes: []string{"feat"}, Increment: IncrementMinor}},
+		{
+			{CommonName: "Features", Prefixes: []string{"feat"}, Increment: IncrementMinor},
+			{CommonName: "New", Prefixes: []string{"FEAT"}, Increment: IncrementMinor},
+		},
+	}
+	for _, changeTypes := range invalid {
+		if err := changeTypes.Validate(); err == nil {
+			t.Errorf("expected an error for %+v", changeTypes)
+		}
+	}
+}
+
+func TestChangeTypesSections(t *testing.T) {
+	changeTypes := ChangeTypes{
+		{Order: 2, CommonName: "Fixes", Prefixes: []string{"fix"}, Increment: IncrementPatch},
+		{Order: 1, CommonName: "Features", Prefixes: []string{"feat"}, Increment: IncrementMinor},
+		{Order: 0, CommonName: "Chores", Prefixes: []string{"chore"}, Increment: IncrementNone, Hidden: true},
+	}
+
+	sections := changeTypes.Sections()
+	if len(sections) != 2 || sections[0].CommonName != "Features" || sections[1].CommonName != "Fixes" {
+		t.Errorf("expected Features and Fixes sections, got %+v", sections)
+	}
+}
@@ -0,0 +1,68 @@
+package conventionalcommits
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Message is the content of a conventional commit message before it is written.
+type Message struct {
+	Type     string
+	Scope    string
+	Subject  string
+	Body     string
+	Breaking string
+	Issues   []string
+}
+
+// String returns the commit message: the header, the body and the footers for the breaking change and the issue
+// references, separated by blank lines.
+func (m Message) String() string {
+	var sb strings.Builder
+	breaking := strings.TrimSpace(m.Breaking)
+
+	sb.WriteString(m.Type)
+	if len(m.Scope) > 0 {
+		sb.WriteString(fmt.Sprintf("(%s)", m.Scope))
+	}
+	if len(breaking) > 0 {
+		sb.WriteString("!")
+	}
+	sb.WriteString(": ")
+	sb.WriteString(strings.TrimSpace(m.Subject))
+
+	if body := trimBody(m.Body); len(body) > 0 {
+		sb.WriteString("\n\n")
+		sb.WriteString(body)
+	}
+
+	footers := make([]string, 0)
+	if len(breaking) > 0 {
+		footers = append(footers, "BREAKING CHANGE: "+breaking)
+	}
+	issues := make([]string, 0)
+	for _, issue := range m.Issues {
+		if issue = strings.TrimSpace(issue); len(issue) > 0 {
+			issues = append(issues, issue)
+		}
+	}
+	if len(issues) > 0 {
+		footers = append(footers, "Refs: "+strings.Join(issues, ", "))
+	}
+	if len(footers) > 0 {
+		sb.WriteString("\n\n")
+		sb.WriteString(strings.Join(footers, "\n"))
+	}
+
+	return sb.String()
+}
+
+// trimBody removes the trailing spaces of each line of body and its leading and trailing blank lines, keeping the
+// indentation of its lines.
+func trimBody(body string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,35 @@
+package conventionalcommits
+
+import "testing"
+
+func TestMessageString(t *testing.T) {
+	message := Message{
+		Type:     "feat",
+		Scope:    "api",
+		Subject:  "drop v1 endpoints ",
+		Body:     "The v1 endpoints were deprecated a year ago.",
+		Breaking: "v1 endpoints are gone",
+		Issues:   []string{"#12", " ", "#14"},
+	}
+
+	expected := "feat(api)!: drop v1 endpoints\n\n" +
+		"The v1 endpoints were deprecated a year ago.\n\n" +
+		"BREAKING CHANGE: v1 endpoints are gone\n" +
+		"Refs: #12, #14"
+	if message.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, message.String())
+	}
+	if reasons := Check(message.String(), DefaultChangeTypes(), CheckRules{}); len(reasons) != 0 {
+		t.Errorf("expected the built message to pass the check, got %v", reasons)
+	}
+
+	if got := (Message{Type: "fix", Subject: "typo"}).String(); got != "fix: typo" {
+		t.Errorf("expected header only, got %q", got)
+	}
+
+	message = Message{Type: "fix", Subject: "typo", Body: "\n  - indented first line  \n  - second\n \n", Breaking: "  "}
+	expected = "fix: typo\n\n  - indented first line\n  - second"
+	if message.String() != expected {
+		t.Errorf("expected the body indentation kept and no breaking change, got %q", message.String())
+	}
+}
@@ -0,0 +1,195 @@
+package diff
+
+// Ref: http://www.xmailserver.org/diff2.pdf (An O(ND) Difference Algorithm and Its Variations, E. Myers)
+
+import (
+	"fmt"
+	"strings"
+)
+
+const contextLines = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified returns the unified diff between before and after, labelled with fromName and toName. It returns an empty
+// string when both contents are equal.
+func Unified(fromName string, toName string, before []byte, after []byte) string {
+	ops := diffLines(splitLines(string(before)), splitLines(string(after)))
+
+	hunks := buildHunks(ops)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n", fromName))
+	sb.WriteString(fmt.Sprintf("+++ %s\n", toName))
+	for _, h := range hunks {
+		sb.WriteString(h)
+	}
+	return sb.String()
+}
+
+// Colorize adds terminal colors to a diff returned by Unified: removed lines in red, added lines in green and hunk
+// headers in cyan.
+func Colorize(d string) string {
+	lines := splitLines(d)
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++"):
+			lines[i] = "\033[1m" + line + "\033[0m"
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = "\033[36m" + line + "\033[0m"
+		case strings.HasPrefix(line, "-"):
+			lines[i] = "\033[31m" + line + "\033[0m"
+		case strings.HasPrefix(line, "+"):
+			lines[i] = "\033[32m" + line + "\033[0m"
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func splitLines(s string) []string {
+	if len(s) == 0 {
+		return []string{}
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func diffLines(a []string, b []string) []op {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return []op{}
+	}
+
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	trace := make([][]int, 0)
+
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+				x = v[k+1+offset]
+			} else {
+				x = v[k-1+offset] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+offset] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, offset)
+			}
+		}
+	}
+	return backtrack(a, b, trace, offset)
+}
+
+func backtrack(a []string, b []string, trace [][]int, offset int) []op {
+	ops := make([]op, 0)
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+offset]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, op{kind: opEqual, line: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, op{kind: opInsert, line: b[y-1]})
+			} else {
+				ops = append(ops, op{kind: opDelete, line: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+func buildHunks(ops []op) []string {
+	// Ranges of op indexes to print, with their context already added
+	ranges := make([][2]int, 0)
+	for i, o := range ops {
+		if o.kind == opEqual {
+			continue
+		}
+		start, end := max(i-contextLines, 0), min(i+contextLines+1, len(ops))
+		if len(ranges) > 0 && start <= ranges[len(ranges)-1][1] {
+			ranges[len(ranges)-1][1] = end
+		} else {
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+
+	// Line numbers (0-based) of both sides at each op index
+	aLines, bLines := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for i, o := range ops {
+		aLines[i+1], bLines[i+1] = aLines[i], bLines[i]
+		if o.kind != opInsert {
+			aLines[i+1]++
+		}
+		if o.kind != opDelete {
+			bLines[i+1]++
+		}
+	}
+
+	hunks := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		var sb strings.Builder
+		aStart, aCount := aLines[r[0]], aLines[r[1]]-aLines[r[0]]
+		bStart, bCount := bLines[r[0]], bLines[r[1]]-bLines[r[0]]
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount)))
+		for _, o := range ops[r[0]:r[1]] {
+			sb.WriteString(fmt.Sprintf("%c%s\n", o.kind, o.line))
+		}
+		hunks = append(hunks, sb.String())
+	}
+	return hunks
+}
+
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
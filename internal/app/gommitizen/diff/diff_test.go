@@ -0,0 +1,52 @@
+package diff
+
+import (
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	before := "name: chart\nversion: 1.2.3\nappVersion: 1.2.3\ndescription: a\nb\nc\nd\ne\nf\ng\nh\nlast: 1.2.3\n"
+	after := "name: chart\nversion: 1.3.0\nappVersion: 1.2.3\ndescription: a\nb\nc\nd\ne\nf\ng\nh\nlast: 1.3.0\n"
+
+	want := `--- a/Chart.yaml
++++ b/Chart.yaml
+@@ -1,5 +1,5 @@
+ name: chart
+-version: 1.2.3
++version: 1.3.0
+ appVersion: 1.2.3
+ description: a
+ b
+@@ -9,4 +9,4 @@
+ f
+ g
+ h
+-last: 1.2.3
++last: 1.3.0
+`
+	got := Unified("a/Chart.yaml", "b/Chart.yaml", []byte(before), []byte(after))
+	if got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnifiedNewFileAndNoChanges(t *testing.T) {
+	if got := Unified("a", "b", []byte("same\n"), []byte("same\n")); got != "" {
+		t.Errorf("expected no diff, got\n%s", got)
+	}
+
+	want := "--- /dev/null\n+++ b/CHANGELOG.md\n@@ -0,0 +1,2 @@\n+# 1.0.0\n+\n"
+	if got := Unified("/dev/null", "b/CHANGELOG.md", nil, []byte("# 1.0.0\n\n")); got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestColorize(t *testing.T) {
+	d := "--- a\n+++ b\n@@ -1 +1 @@\n-old\n+new\n same\n"
+
+	want := "\033[1m--- a\033[0m\n\033[1m+++ b\033[0m\n\033[36m@@ -1 +1 @@\033[0m\n" +
+		"\033[31m-old\033[0m\n\033[32m+new\033[0m\n same\n"
+	if got := Colorize(d); got != want {
+		t.Errorf("Colorize() =\n%q\nwant\n%q", got, want)
+	}
+}
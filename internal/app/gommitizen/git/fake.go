@@ -0,0 +1,385 @@
+package git
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FakeCommit is a commit stored by FakeRepository together with the paths it touched and, for the files written
+// with AddFileCommit, their content.
+type FakeCommit struct {
+	Commit
+	Paths []string
+	Files map[string]string
+}
+
+// FakeRepository is an in-memory Repository. Commits are kept oldest first, as they would be created.
+type FakeRepository struct {
+	Commits     []FakeCommit
+	Tags        map[string]string
+	Annotations map[string]TagAnnotation
+	Staged      []string
+	GitDir      string
+	Remotes     map[string]string
+	// WorkDir, when set, is the working directory ListFiles lists paths from: the absolute paths under it are listed
+	// relative to it, as git would.
+	WorkDir string
+}
+
+var _ Repository = (*FakeRepository)(nil)
+
+func NewFakeRepository() *FakeRepository {
+	return &FakeRepository{
+		Commits:     make([]FakeCommit, 0),
+		Tags:        make(map[string]string),
+		Annotations: make(map[string]TagAnnotation),
+		Staged:      make([]string, 0),
+		Remotes:     make(map[string]string),
+	}
+}
+
+// AddCommit records a commit touching the given paths and returns its hash. The first line of the message is the
+// subject.
+func (r *FakeRepository) AddCommit(message string, paths ...string) string {
+	hash := fakeHash(fmt.Sprintf("%d|%s|%s", len(r.Commits), message, strings.Join(paths, ",")))
+	r.Commits = append(r.Commits, FakeCommit{
+		Commit: Commit{
+			Hash:    hash,
+			Date:    time.Now().UTC().Truncate(time.Second),
+			Subject: strings.SplitN(message, "\n", 2)[0],
+			Message: strings.TrimSpace(message),
+		},
+		Paths: paths,
+	})
+	return hash
+}
+
+// AddFileCommit records a commit writing content to filePath and returns its hash.
+func (r *FakeRepository) AddFileCommit(message string, filePath string, content string) string {
+	hash := r.AddCommit(message, filePath)
+	r.Commits[len(r.Commits)-1].Files = map[string]string{filePath: content}
+	return hash
+}
+
+func (r *FakeRepository) GetFirstCommit() (string, error) {
+	if len(r.Commits) == 0 {
+		return "", fmt.Errorf("fail first commit: repository has no commits")
+	}
+	return r.Commits[0].Hash, nil
+}
+
+func (r *FakeRepository) GetLastCommit() (string, error) {
+	if len(r.Commits) == 0 {
+		return "", fmt.Errorf("fail last commit: repository has no commits")
+	}
+	return r.Commits[len(r.Commits)-1].Hash, nil
+}
+
+func (r *FakeRepository) GetCommits(fromCommit string, pathspecs ...string) ([]Commit, error) {
+	return r.GetCommitsBetween(fromCommit, "", pathspecs...)
+}
+
+// GetCommitsBetween supports plain paths and the exclude, glob and top magic words. The fake has no working directory,
+// so top paths are matched as they are.
+func (r *FakeRepository) GetCommitsBetween(fromCommit string, toCommit string, pathspecs ...string) ([]Commit, error) {
+	start, end := -1, len(r.Commits)-1
+	if len(fromCommit) > 0 {
+		if start = r.indexOf(fromCommit); start == -1 {
+			return []Commit{}, fmt.Errorf("fail log: unknown revision %s", fromCommit)
+		}
+	}
+	if len(toCommit) > 0 {
+		if end = r.indexOf(toCommit); end == -1 {
+			return []Commit{}, fmt.Errorf("fail log: unknown revision %s", toCommit)
+		}
+	}
+
+	commits := make([]Commit, 0)
+	for i := end; i > start; i-- {
+		if touchesPathspecs(r.Commits[i].Paths, pathspecs) {
+			commits = append(commits, r.Commits[i].Commit)
+		}
+	}
+	return commits, nil
+}
+
+// GetCommitsInRange supports `from..to`, `from..` and a single revision.
+func (r *FakeRepository) GetCommitsInRange(revisionRange string) ([]Commit, error) {
+	from, to := "", revisionRange
+	if index := strings.Index(revisionRange, ".."); index != -1 {
+		from, to = revisionRange[:index], revisionRange[index+2:]
+	}
+
+	start, end := -1, len(r.Commits)-1
+	for i, commit := range r.Commits {
+		if len(from) > 0 && commit.Hash == from {
+			start = i
+		}
+		if len(to) > 0 && commit.Hash == to {
+			end = i
+		}
+	}
+	if len(from) > 0 && start == -1 {
+		return []Commit{}, fmt.Errorf("fail log: unknown revision %s", from)
+	}
+	if len(to) > 0 && (end < 0 || r.Commits[end].Hash != to) {
+		return []Commit{}, fmt.Errorf("fail log: unknown revision %s", to)
+	}
+
+	commits := make([]Commit, 0)
+	for i := end; i > start; i-- {
+		commits = append(commits, r.Commits[i].Commit)
+	}
+	return commits, nil
+}
+
+// GetFileAt returns the content written to filePath by the last AddFileCommit up to commit.
+func (r *FakeRepository) GetFileAt(commit string, filePath string) ([]byte, error) {
+	index := r.indexOf(commit)
+	if index == -1 {
+		return nil, fmt.Errorf("fail show: unknown revision %s", commit)
+	}
+	for i := index; i >= 0; i-- {
+		if content, ok := r.Commits[i].Files[filePath]; ok {
+			return []byte(content), nil
+		}
+	}
+	return nil, fmt.Errorf("fail show: path '%s' does not exist in '%s'", filePath, commit)
+}
+
+// ListFiles returns the paths touched by any commit or staged that match the pathspecs, sorted.
+func (r *FakeRepository) ListFiles(pathspecs ...string) ([]string, error) {
+	paths := append([]string{}, r.Staged...)
+	for _, commit := range r.Commits {
+		paths = append(paths, commit.Paths...)
+	}
+
+	files := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if len(r.WorkDir) > 0 && filepath.IsAbs(path) {
+			relPath, err := filepath.Rel(r.WorkDir, path)
+			if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+				continue
+			}
+			path = relPath
+		}
+		if seen[path] || !touchesPathspecs([]string{path}, pathspecs) {
+			continue
+		}
+		seen[path] = true
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (r *FakeRepository) AddFilePath(filePath string) (string, error) {
+	r.Staged = append(r.Staged, filePath)
+	return "", nil
+}
+
+func (r *FakeRepository) CreateCommit(message string) (string, error) {
+	if len(r.Staged) == 0 {
+		return "", fmt.Errorf("fail commit: nothing added to commit")
+	}
+	r.AddCommit(message, r.Staged...)
+	r.Staged = make([]string, 0)
+	return "", nil
+}
+
+func (r *FakeRepository) CreateCommitAt(message string, date time.Time) (string, error) {
+	if _, err := r.CreateCommit(message); err != nil {
+		return "", err
+	}
+	r.Commits[len(r.Commits)-1].Date = date.UTC().Truncate(time.Second)
+	return "", nil
+}
+
+func (r *FakeRepository) CreateTag(tag string) (string, error) {
+	if _, ok := r.Tags[tag]; ok {
+		return "", fmt.Errorf("fail tag: tag '%s' already exists", tag)
+	}
+	head, err := r.GetLastCommit()
+	if err != nil {
+		return "", err
+	}
+	r.Tags[tag] = head
+	return "", nil
+}
+
+func (r *FakeRepository) CreateTagAt(tag string, commit string) (string, error) {
+	if _, ok := r.Tags[tag]; ok {
+		return "", fmt.Errorf("fail tag: tag '%s' already exists", tag)
+	}
+	for _, c := range r.Commits {
+		if c.Hash == commit {
+			r.Tags[tag] = commit
+			return "", nil
+		}
+	}
+	return "", fmt.Errorf("fail tag: unknown revision %s", commit)
+}
+
+// CreateAnnotatedTagAt creates a tag of commit and records its annotation in Annotations.
+func (r *FakeRepository) CreateAnnotatedTagAt(tag string, commit string, annotation TagAnnotation) (string, error) {
+	if _, err := r.CreateTagAt(tag, commit); err != nil {
+		return "", err
+	}
+	r.Annotations[tag] = annotation
+	return "", nil
+}
+
+func (r *FakeRepository) GetTags() ([]Tag, error) {
+	tags := make([]Tag, 0, len(r.Tags))
+	for name, commit := range r.Tags {
+		tag := Tag{Name: name, Commit: commit}
+		if index := r.indexOf(commit); index != -1 {
+			tag.Date = r.Commits[index].Date
+		}
+		if annotation, ok := r.Annotations[name]; ok {
+			tag.Annotation = &annotation
+		}
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (r *FakeRepository) DeleteTag(tag string) (string, error) {
+	if _, ok := r.Tags[tag]; !ok {
+		return "", fmt.Errorf("fail tag: tag '%s' not found", tag)
+	}
+	delete(r.Tags, tag)
+	delete(r.Annotations, tag)
+	return "", nil
+}
+
+func (r *FakeRepository) ResetHead(commit string) (string, error) {
+	for i := len(r.Commits) - 1; i >= 0; i-- {
+		if r.Commits[i].Hash == commit {
+			for _, undone := range r.Commits[i+1:] {
+				r.Staged = append(r.Staged, undone.Paths...)
+			}
+			r.Commits = r.Commits[:i+1]
+			return "", nil
+		}
+	}
+	return "", fmt.Errorf("fail reset: unknown revision %s", commit)
+}
+
+// GetIndexEntry returns an entry for the staged files. The fake index does not hold the files that are not staged.
+func (r *FakeRepository) GetIndexEntry(filePath string) (*IndexEntry, error) {
+	for _, path := range r.Staged {
+		if path == filePath {
+			return &IndexEntry{Mode: "100644", Blob: fakeHash(path), Path: path}, nil
+		}
+	}
+	return nil, nil
+}
+
+// RestoreIndexEntry stages filePath once when entry is set, and unstages it otherwise.
+func (r *FakeRepository) RestoreIndexEntry(filePath string, entry *IndexEntry) (string, error) {
+	staged := make([]string, 0, len(r.Staged))
+	for _, path := range r.Staged {
+		if path != filePath {
+			staged = append(staged, path)
+		}
+	}
+	if entry != nil {
+		staged = append(staged, filePath)
+	}
+	r.Staged = staged
+	return "", nil
+}
+
+func (r *FakeRepository) GetStagedFiles() ([]string, error) {
+	return append([]string{}, r.Staged...), nil
+}
+
+func (r *FakeRepository) GetGitDir() (string, error) {
+	if len(r.GitDir) == 0 {
+		return "", fmt.Errorf("fail rev-parse: not a git repository")
+	}
+	return r.GitDir, nil
+}
+
+func (r *FakeRepository) GetRemoteURL(remote string) (string, error) {
+	url, ok := r.Remotes[remote]
+	if !ok {
+		return "", fmt.Errorf("fail remote: no such remote '%s'", remote)
+	}
+	return url, nil
+}
+
+// indexOf returns the position of a commit in Commits, or -1.
+func (r *FakeRepository) indexOf(hash string) int {
+	for i, commit := range r.Commits {
+		if commit.Hash == hash {
+			return i
+		}
+	}
+	return -1
+}
+
+// touchesPathspecs tells whether any of paths is matched by an included pathspec and by no excluded one. Without
+// included pathspecs, every path is included.
+func touchesPathspecs(paths []string, pathspecs []string) bool {
+	includes, excludes := make([]string, 0), make([]string, 0)
+	for _, pathspec := range pathspecs {
+		if _, magic := parsePathspec(pathspec); magic[PathspecExclude] {
+			excludes = append(excludes, pathspec)
+		} else {
+			includes = append(includes, pathspec)
+		}
+	}
+	if len(includes) == 0 {
+		includes = append(includes, ".")
+	}
+
+	for _, path := range paths {
+		included, excluded := false, false
+		for _, include := range includes {
+			included = included || matchPathspec(include, path)
+		}
+		for _, exclude := range excludes {
+			excluded = excluded || matchPathspec(exclude, path)
+		}
+		if included && !excluded {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPathspec(pathspec string, path string) bool {
+	pathspecPath, magic := parsePathspec(pathspec)
+	if magic[PathspecGlob] {
+		return matchGlobPathspec(filepath.ToSlash(pathspecPath), filepath.ToSlash(path))
+	}
+	return touchesPath([]string{path}, pathspecPath)
+}
+
+func touchesPath(paths []string, fromPath string) bool {
+	fromPath = filepath.Clean(fromPath)
+	if fromPath == "." {
+		return true
+	}
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		if path == fromPath || strings.HasPrefix(path, fromPath+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func fakeHash(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,89 +1,382 @@
 package git
 
 import (
+	"bytes"
 	"fmt"
 	"log/slog"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
 
-func GetFirstCommit() (string, error) {
-	cmd := "git rev-list --max-parents=0 HEAD"
-	slog.Debug(fmt.Sprintf("exec: %s", cmd))
-	output, err := exec.Command("bash", "-c", cmd).Output()
+// Repository is the set of git operations gommitizen relies on. It lets commands and managers work against a real
+// repository or against an in-memory fake in tests.
+type Repository interface {
+	GetFirstCommit() (string, error)
+	GetLastCommit() (string, error)
+	GetCommits(fromCommit string, pathspecs ...string) ([]Commit, error)
+	GetCommitsBetween(fromCommit string, toCommit string, pathspecs ...string) ([]Commit, error)
+	GetCommitsInRange(revisionRange string) ([]Commit, error)
+	GetFileAt(commit string, filePath string) ([]byte, error)
+	ListFiles(pathspecs ...string) ([]string, error)
+	AddFilePath(filePath string) (string, error)
+	CreateCommit(message string) (string, error)
+	CreateCommitAt(message string, date time.Time) (string, error)
+	CreateTag(tag string) (string, error)
+	CreateTagAt(tag string, commit string) (string, error)
+	CreateAnnotatedTagAt(tag string, commit string, annotation TagAnnotation) (string, error)
+	GetTags() ([]Tag, error)
+	DeleteTag(tag string) (string, error)
+	ResetHead(commit string) (string, error)
+	GetIndexEntry(filePath string) (*IndexEntry, error)
+	RestoreIndexEntry(filePath string, entry *IndexEntry) (string, error)
+	GetStagedFiles() ([]string, error)
+	GetGitDir() (string, error)
+	GetRemoteURL(remote string) (string, error)
+}
+
+// CommandRepository runs the git binary with an argument list, never through a shell, inside a working directory.
+type CommandRepository struct {
+	dirPath string
+}
+
+var _ Repository = (*CommandRepository)(nil)
+
+func NewRepository(dirPath string) *CommandRepository {
+	return &CommandRepository{dirPath: dirPath}
+}
+
+// run runs git and returns its output trimmed, for commands printing a single value. Outputs listing paths with -z are
+// read with runRaw, as paths may start or end with spaces.
+func (r *CommandRepository) run(args ...string) (string, error) {
+	output, err := r.runRaw(args...)
+	return strings.TrimSpace(string(output)), err
+}
+
+// runRaw runs git and returns its output as it is, for the content of files and lists of paths.
+func (r *CommandRepository) runRaw(args ...string) ([]byte, error) {
+	return r.runRawEnv(nil, args...)
+}
+
+// runRawEnv runs git with env added to the environment of the process.
+func (r *CommandRepository) runRawEnv(env []string, args ...string) ([]byte, error) {
+	slog.Debug(fmt.Sprintf("exec: git %s", strings.Join(args, " ")))
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dirPath
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return nil, fmt.Errorf("fail git %s: %v: %s", strings.Join(args, " "), err, msg)
+		}
+		return nil, fmt.Errorf("fail git %s: %v", strings.Join(args, " "), err)
+	}
+	return output, nil
+}
+
+func (r *CommandRepository) GetFirstCommit() (string, error) {
+	return r.run("rev-list", "--max-parents=0", "HEAD")
+}
+
+func (r *CommandRepository) AddFilePath(filePath string) (string, error) {
+	return r.run("add", "--", filePath)
+}
+
+func (r *CommandRepository) CreateTag(tag string) (string, error) {
+	return r.run("tag", tag)
+}
+
+func (r *CommandRepository) CreateTagAt(tag string, commit string) (string, error) {
+	return r.run("tag", tag, commit)
+}
+
+// CreateAnnotatedTagAt creates an annotated tag of commit with the message, tagger and date of annotation.
+func (r *CommandRepository) CreateAnnotatedTagAt(tag string, commit string, annotation TagAnnotation) (string, error) {
+	env := []string{
+		"GIT_COMMITTER_NAME=" + annotation.TaggerName,
+		"GIT_COMMITTER_EMAIL=" + annotation.TaggerEmail,
+		"GIT_COMMITTER_DATE=" + annotation.TaggerDate.Format("2006-01-02T15:04:05-0700"),
+	}
+	output, err := r.runRawEnv(env, "tag", "-a", "--cleanup=verbatim", "-m", annotation.Message, tag, commit)
+	return strings.TrimSpace(string(output)), err
+}
+
+// GetTags returns every tag of the repository with the commit it points to and the date of that commit, annotated
+// tags are peeled and come with their annotation.
+func (r *CommandRepository) GetTags() ([]Tag, error) {
+	output, err := r.run(
+		"for-each-ref",
+		"--format=%(objectname)%1f%(*objectname)%1f%(committerdate:unix)%1f%(*committerdate:unix)%1f%(refname:strip=2)"+
+			"%1f%(objecttype)%1f%(taggername)%1f%(taggeremail)%1f%(taggerdate:raw)%1f%(contents:subject)%1f%(contents:body)%1e",
+		"refs/tags",
+	)
 	if err != nil {
-		return "", fmt.Errorf("fail %s: %v", cmd, err)
+		return []Tag{}, err
+	}
+
+	tags := make([]Tag, 0)
+	for _, record := range strings.Split(output, logRecordSeparator) {
+		record = strings.TrimLeft(record, "\n")
+		if len(record) == 0 {
+			continue
+		}
+		fields := strings.SplitN(record, logFieldSeparator, 11)
+		if len(fields) != 11 {
+			return []Tag{}, fmt.Errorf("fail parsing tag record %q", record)
+		}
+		commit, date := fields[0], fields[2]
+		if len(fields[1]) > 0 {
+			commit, date = fields[1], fields[3]
+		}
+		tag := Tag{Name: fields[4], Commit: commit}
+		// Tags of objects other than commits have no commit date
+		if seconds, err := strconv.ParseInt(date, 10, 64); err == nil {
+			tag.Date = time.Unix(seconds, 0).UTC()
+		}
+		if fields[5] == "tag" {
+			tag.Annotation = parseTagAnnotation(fields[6:])
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// parseTagAnnotation parses the tagger name, email and date, and the subject and body of an annotated tag.
+func parseTagAnnotation(fields []string) *TagAnnotation {
+	annotation := &TagAnnotation{
+		Message:     fields[3],
+		TaggerName:  fields[0],
+		TaggerEmail: strings.Trim(fields[1], "<>"),
+	}
+	if body := strings.TrimSpace(fields[4]); len(body) > 0 {
+		annotation.Message += "\n\n" + body
 	}
-	return strings.TrimSpace(string(output)), nil
+	annotation.TaggerDate = parseRawDate(fields[2])
+	return annotation
 }
 
-func AddFilePath(filePath string) (string, error) {
-	cmd := fmt.Sprintf("git add %s", filePath)
-	slog.Debug(fmt.Sprintf("exec: %s", cmd))
-	output, err := exec.Command("bash", "-c", cmd).Output()
+// parseRawDate parses a date in the raw format of git, seconds since the epoch and the time zone offset of whoever
+// made the object, keeping the offset. Dates that fail to parse are zero.
+func parseRawDate(raw string) time.Time {
+	secondsField, offsetField, _ := strings.Cut(raw, " ")
+	seconds, err := strconv.ParseInt(secondsField, 10, 64)
 	if err != nil {
-		return "", fmt.Errorf("fail %s: %v", cmd, err)
+		return time.Time{}
 	}
-	return strings.TrimSpace(string(output)), nil
+	offset, err := time.Parse("-0700", offsetField)
+	if err != nil {
+		return time.Unix(seconds, 0).UTC()
+	}
+	_, offsetSeconds := offset.Zone()
+	if offsetSeconds == 0 {
+		return time.Unix(seconds, 0).UTC()
+	}
+	return time.Unix(seconds, 0).In(time.FixedZone("", offsetSeconds))
 }
 
-func CreateTag(tag string) (string, error) {
-	cmd := fmt.Sprintf("git tag %s", tag)
-	slog.Debug(fmt.Sprintf("exec: %s", cmd))
-	output, err := exec.Command("bash", "-c", cmd).Output()
+// ListFiles returns the tracked and untracked files matching the pathspecs, leaving out the files ignored by git. Paths
+// are relative to the working directory of the repository.
+func (r *CommandRepository) ListFiles(pathspecs ...string) ([]string, error) {
+	args := append([]string{"ls-files", "-z", "--cached", "--others", "--exclude-standard", "--"}, pathspecs...)
+	output, err := r.runRaw(args...)
 	if err != nil {
-		return "", fmt.Errorf("fail %s: %v", cmd, err)
+		return []string{}, err
+	}
+
+	files := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, file := range strings.Split(string(output), "\x00") {
+		// Files in conflict are listed once per stage
+		if len(file) == 0 || seen[file] {
+			continue
+		}
+		seen[file] = true
+		files = append(files, file)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return files, nil
 }
 
-func CreateCommit(message string) (string, error) {
-	cmd := fmt.Sprintf(`git commit -m "%s"`, message)
-	slog.Debug(fmt.Sprintf("exec: %s", cmd))
-	output, err := exec.Command("bash", "-c", cmd).Output()
+func (r *CommandRepository) DeleteTag(tag string) (string, error) {
+	return r.run("tag", "-d", tag)
+}
+
+// ResetHead moves HEAD to commit keeping the index and the working tree (a soft reset).
+func (r *CommandRepository) ResetHead(commit string) (string, error) {
+	return r.run("reset", "--soft", commit)
+}
+
+// GetIndexEntry returns the entry of a file in the index, or nil when the file is not in the index.
+func (r *CommandRepository) GetIndexEntry(filePath string) (*IndexEntry, error) {
+	output, err := r.runRaw("ls-files", "--stage", "--full-name", "-z", "--", filePath)
 	if err != nil {
-		return "", fmt.Errorf("fail %s: %v", cmd, err)
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(output), "\x00") {
+		if len(line) == 0 {
+			continue
+		}
+		info, path, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(info)
+		if !ok || len(fields) != 3 {
+			return nil, fmt.Errorf("fail parsing index entry %q", line)
+		}
+		// Files in conflict have no entry at stage 0
+		if fields[2] == "0" {
+			return &IndexEntry{Mode: fields[0], Blob: fields[1], Path: path}, nil
+		}
+	}
+	return nil, nil
+}
+
+// RestoreIndexEntry puts an entry returned by GetIndexEntry back in the index, or removes the file from the index
+// when entry is nil. The content of the file in the working tree is left as it is.
+func (r *CommandRepository) RestoreIndexEntry(filePath string, entry *IndexEntry) (string, error) {
+	if entry == nil {
+		return r.run("update-index", "--force-remove", "--", filePath)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return r.run("update-index", "--add", "--cacheinfo", entry.Mode+","+entry.Blob+","+entry.Path)
 }
 
-func GetLastCommit() (string, error) {
-	cmd := "git rev-parse HEAD"
-	slog.Debug(fmt.Sprintf("exec: %s", cmd))
-	output, err := exec.Command("bash", "-c", cmd).Output()
+// GetStagedFiles returns the files staged for the next commit under the working directory, relative to it.
+func (r *CommandRepository) GetStagedFiles() ([]string, error) {
+	output, err := r.runRaw("diff", "--cached", "--name-only", "--relative", "-z")
 	if err != nil {
-		return "", fmt.Errorf("fail %s: %v", cmd, err)
+		return []string{}, err
 	}
-	return strings.TrimSpace(string(output)), nil
+
+	files := make([]string, 0)
+	for _, file := range strings.Split(string(output), "\x00") {
+		if len(file) > 0 {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// GetGitDir returns the absolute path of the .git directory of the repository.
+func (r *CommandRepository) GetGitDir() (string, error) {
+	return r.run("rev-parse", "--absolute-git-dir")
+}
+
+// GetRemoteURL returns the URL a remote of the repository fetches from.
+func (r *CommandRepository) GetRemoteURL(remote string) (string, error) {
+	return r.run("remote", "get-url", remote)
+}
+
+func (r *CommandRepository) CreateCommit(message string) (string, error) {
+	return r.run("commit", "-m", message)
+}
+
+// CreateCommitAt creates a commit whose author and committer dates are date.
+func (r *CommandRepository) CreateCommitAt(message string, date time.Time) (string, error) {
+	gitDate := date.Format(time.RFC3339)
+	output, err := r.runRawEnv([]string{"GIT_AUTHOR_DATE=" + gitDate, "GIT_COMMITTER_DATE=" + gitDate}, "commit", "-m", message)
+	return strings.TrimSpace(string(output)), err
+}
+
+func (r *CommandRepository) GetLastCommit() (string, error) {
+	return r.run("rev-parse", "HEAD")
 }
 
 // https://git-scm.com/docs/pretty-formats
-func GetCommits(fromCommit string, fromPath string) ([]Commit, error) {
-	pretty := `--pretty=format:'%H||%ad||%s'`
-	dateFormat := `--date=format-local:'%Y-%m-%dT%H:%M:%SZ'`
-	cmd := fmt.Sprintf(`git log %s %s %s.. -- %s`, pretty, dateFormat, fromCommit, fromPath)
-	slog.Debug(fmt.Sprintf("exec: %s", cmd))
+const (
+	logFieldSeparator  = "\x1f"
+	logRecordSeparator = "\x1e"
+)
+
+func (r *CommandRepository) GetCommits(fromCommit string, pathspecs ...string) ([]Commit, error) {
+	return r.GetCommitsBetween(fromCommit, "", pathspecs...)
+}
+
+// GetCommitsBetween returns the commits after fromCommit up to toCommit that touch the files matched by pathspecs,
+// newest first. An empty toCommit stands for HEAD, and an empty fromCommit for the start of the history.
+func (r *CommandRepository) GetCommitsBetween(fromCommit string, toCommit string, pathspecs ...string) ([]Commit, error) {
+	revisionRange := fromCommit + ".." + toCommit
+	if len(fromCommit) == 0 {
+		revisionRange = toCommit
+		if len(revisionRange) == 0 {
+			revisionRange = "HEAD"
+		}
+	}
+
+	return r.log(revisionRange, pathspecs...)
+}
+
+// GetCommitsInRange returns the commits of a revision range like `main..HEAD`, or of a single revision and its
+// ancestors, newest first. A range starting with a dash is rejected, git would take it for an option.
+func (r *CommandRepository) GetCommitsInRange(revisionRange string) ([]Commit, error) {
+	if strings.HasPrefix(revisionRange, "-") {
+		return []Commit{}, fmt.Errorf("invalid revision range %q", revisionRange)
+	}
+	return r.log(revisionRange)
+}
 
-	output, err := exec.Command("bash", "-c", cmd).Output()
+// log returns the commits of a revision range that touch the files matched by pathspecs, newest first.
+func (r *CommandRepository) log(revisionRange string, pathspecs ...string) ([]Commit, error) {
+	args := []string{
+		"log",
+		"--pretty=format:%H%x1f%ad%x1f%s%x1f%B%x1e",
+		"--date=format-local:%Y-%m-%dT%H:%M:%SZ",
+		revisionRange,
+		"--",
+	}
+	output, err := r.run(append(args, pathspecs...)...)
 	if err != nil {
-		return []Commit{}, fmt.Errorf("fail %s: %v", cmd, err)
+		return []Commit{}, err
+	}
+
+	return parseLog(output)
+}
+
+// GetFileAt returns the content a file had at a commit, byte for byte. Relative paths are relative to the working directory.
+func (r *CommandRepository) GetFileAt(commit string, filePath string) ([]byte, error) {
+	if filepath.IsAbs(filePath) {
+		dirPath, err := filepath.Abs(r.dirPath)
+		if err != nil {
+			return nil, err
+		}
+		filePath, err = filepath.Rel(dirPath, filePath)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	return r.runRaw("show", commit+":./"+filepath.ToSlash(filePath))
+}
+
+func parseLog(output string) ([]Commit, error) {
 	commits := make([]Commit, 0)
-	for _, line := range strings.Split(string(output), "\n") {
-		if len(line) > 0 {
-			hash, date, subject := strings.Split(line, "||")[0], strings.Split(line, "||")[1], strings.Split(line, "||")[2]
+	for _, record := range strings.Split(output, logRecordSeparator) {
+		record = strings.TrimLeft(record, "\n")
+		if len(record) == 0 {
+			continue
+		}
 
-			dateTime, err := time.Parse("2006-01-02T15:04:05Z", date)
-			if err != nil {
-				return []Commit{}, fmt.Errorf("fail parsing date %s: %v", date, err)
-			}
-			commit := Commit{Hash: hash, Date: dateTime, Subject: subject}
+		fields := strings.SplitN(record, logFieldSeparator, 4)
+		if len(fields) != 4 {
+			return []Commit{}, fmt.Errorf("fail parsing log record %q", record)
+		}
+		hash, date, subject, message := fields[0], fields[1], fields[2], fields[3]
 
-			slog.Debug(fmt.Sprintf("commit: %v", commit))
-			commits = append(commits, commit)
+		dateTime, err := time.Parse("2006-01-02T15:04:05Z", date)
+		if err != nil {
+			return []Commit{}, fmt.Errorf("fail parsing date %s: %v", date, err)
 		}
+		commit := Commit{Hash: hash, Date: dateTime, Subject: subject, Message: strings.TrimSpace(message)}
+
+		slog.Debug(fmt.Sprintf("commit: %v", commit))
+		commits = append(commits, commit)
 	}
 	return commits, nil
 }
@@ -0,0 +1,479 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func newTestRepository(t *testing.T) (*CommandRepository, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	dirPath := t.TempDir()
+	repo := NewRepository(dirPath)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "test"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		if _, err := repo.run(args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	return repo, dirPath
+}
+
+func TestCommandRepositoryQuotedMessageAndSpacedPath(t *testing.T) {
+	repo, dirPath := newTestRepository(t)
+
+	subDir := filepath.Join(dirPath, "my project")
+	if err := os.Mkdir(subDir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	filePath := filepath.Join(subDir, "file with spaces.txt")
+	if err := os.WriteFile(filePath, []byte("content\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	if _, err := repo.AddFilePath(filePath); err != nil {
+		t.Fatalf("AddFilePath() error = %v", err)
+	}
+	message := `feat: "quoted" subject with $HOME and 'single' quotes`
+	if _, err := repo.CreateCommit(message); err != nil {
+		t.Fatalf("CreateCommit() error = %v", err)
+	}
+
+	first, err := repo.GetFirstCommit()
+	if err != nil {
+		t.Fatalf("GetFirstCommit() error = %v", err)
+	}
+	if err := os.WriteFile(filePath, []byte("changed\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := repo.AddFilePath(filePath); err != nil {
+		t.Fatalf("AddFilePath() error = %v", err)
+	}
+	body := "with a body\n\nBREAKING CHANGE: `$PATH` is ignored"
+	if _, err := repo.CreateCommit(message + "\n\n" + body); err != nil {
+		t.Fatalf("CreateCommit() error = %v", err)
+	}
+
+	commits, err := repo.GetCommits(first, subDir)
+	if err != nil {
+		t.Fatalf("GetCommits() error = %v", err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("expected 1 commit, got %d", len(commits))
+	}
+	if commits[0].Subject != message {
+		t.Errorf("expected subject %s, got %s", message, commits[0].Subject)
+	}
+	if commits[0].Message != message+"\n\n"+body {
+		t.Errorf("expected full message, got %q", commits[0].Message)
+	}
+}
+
+func TestFakeRepositoryGetCommits(t *testing.T) {
+	repo := NewFakeRepository()
+	first := repo.AddCommit("chore: init", "README.md")
+	repo.AddCommit("feat: api change", "services/api/main.go")
+	repo.AddCommit("fix: web change", "services/web/main.go")
+
+	commits, err := repo.GetCommits(first, "services/api")
+	if err != nil {
+		t.Fatalf("GetCommits() error = %v", err)
+	}
+	if len(commits) != 1 || commits[0].Subject != "feat: api change" {
+		t.Errorf("expected only the api commit, got %v", commits)
+	}
+
+	commits, err = repo.GetCommits("", "services", ExcludePathspec("services/web"))
+	if err != nil {
+		t.Fatalf("GetCommits() error = %v", err)
+	}
+	if len(commits) != 1 || commits[0].Subject != "feat: api change" {
+		t.Errorf("expected the web commit to be left out, got %v", commits)
+	}
+
+	repo.AddCommit("docs: api readme", "services/api/README.md")
+	commits, err = repo.GetCommits(first, "services/api", Pathspec("**/*.md", PathspecGlob, PathspecExclude))
+	if err != nil {
+		t.Fatalf("GetCommits() error = %v", err)
+	}
+	if len(commits) != 1 || commits[0].Subject != "feat: api change" {
+		t.Errorf("expected the readme commit to be left out, got %v", commits)
+	}
+
+	if _, err := repo.GetCommits("unknown", "."); err == nil {
+		t.Errorf("expected error for unknown revision")
+	}
+}
+
+func TestCommandRepositoryGetTags(t *testing.T) {
+	repo, dirPath := newTestRepository(t)
+
+	filePath := filepath.Join(dirPath, "file.txt")
+	if err := os.WriteFile(filePath, []byte("content\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := repo.AddFilePath(filePath); err != nil {
+		t.Fatalf("AddFilePath() error = %v", err)
+	}
+	if _, err := repo.CreateCommit("chore: init"); err != nil {
+		t.Fatalf("CreateCommit() error = %v", err)
+	}
+	head, _ := repo.GetLastCommit()
+
+	if _, err := repo.run("tag", "-a", "-m", "annotated", "app-v1.0.0"); err != nil {
+		t.Fatalf("git tag: %v", err)
+	}
+	if _, err := repo.CreateTagAt("1.0.0+app", head); err != nil {
+		t.Fatalf("CreateTagAt() error = %v", err)
+	}
+
+	tags, err := repo.GetTags()
+	if err != nil {
+		t.Fatalf("GetTags() error = %v", err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("expected 2 tags, got %v", tags)
+	}
+	for _, tag := range tags {
+		if tag.Commit != head {
+			t.Errorf("expected tag %s at %s, got %s", tag.Name, head, tag.Commit)
+		}
+		if tag.Date.IsZero() {
+			t.Errorf("expected tag %s to have the date of its commit", tag.Name)
+		}
+	}
+	if tags[0].Annotation != nil {
+		t.Errorf("expected 1.0.0+app to be a lightweight tag, got %+v", tags[0].Annotation)
+	}
+	if tags[1].Annotation == nil || tags[1].Annotation.Message != "annotated" || tags[1].Annotation.TaggerEmail != "test@example.com" {
+		t.Errorf("expected the annotation of app-v1.0.0, got %+v", tags[1].Annotation)
+	}
+}
+
+func TestCommandRepositoryCreateAnnotatedTagAt(t *testing.T) {
+	repo, dirPath := newTestRepository(t)
+
+	filePath := filepath.Join(dirPath, "file.txt")
+	if err := os.WriteFile(filePath, []byte("content\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := repo.AddFilePath(filePath); err != nil {
+		t.Fatalf("AddFilePath() error = %v", err)
+	}
+	if _, err := repo.CreateCommit("chore: init"); err != nil {
+		t.Fatalf("CreateCommit() error = %v", err)
+	}
+	head, _ := repo.GetLastCommit()
+
+	annotation := TagAnnotation{
+		Message:     "release 1.0.0\n\nwith notes",
+		TaggerName:  "releaser",
+		TaggerEmail: "releaser@example.com",
+		TaggerDate:  time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC),
+	}
+	if _, err := repo.CreateAnnotatedTagAt("v1.0.0", head, annotation); err != nil {
+		t.Fatalf("CreateAnnotatedTagAt() error = %v", err)
+	}
+
+	tags, err := repo.GetTags()
+	if err != nil {
+		t.Fatalf("GetTags() error = %v", err)
+	}
+	if len(tags) != 1 || tags[0].Commit != head {
+		t.Fatalf("expected v1.0.0 at %s, got %v", head, tags)
+	}
+	if tags[0].Annotation == nil || *tags[0].Annotation != annotation {
+		t.Errorf("expected annotation %+v, got %+v", annotation, tags[0].Annotation)
+	}
+}
+
+func TestCommandRepositoryCreateAnnotatedTagAtKeepsOffset(t *testing.T) {
+	repo, dirPath := newTestRepository(t)
+
+	filePath := filepath.Join(dirPath, "file.txt")
+	if err := os.WriteFile(filePath, []byte("content\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := repo.AddFilePath(filePath); err != nil {
+		t.Fatalf("AddFilePath() error = %v", err)
+	}
+	if _, err := repo.CreateCommit("chore: init"); err != nil {
+		t.Fatalf("CreateCommit() error = %v", err)
+	}
+	head, _ := repo.GetLastCommit()
+
+	date := time.Date(2024, 2, 1, 10, 0, 0, 0, time.FixedZone("", -(5*60+30)*60))
+	annotation := TagAnnotation{Message: "release 1.0.0", TaggerName: "releaser", TaggerEmail: "releaser@example.com", TaggerDate: date}
+	if _, err := repo.CreateAnnotatedTagAt("v1.0.0", head, annotation); err != nil {
+		t.Fatalf("CreateAnnotatedTagAt() error = %v", err)
+	}
+
+	raw, err := repo.run("for-each-ref", "--format=%(taggerdate:raw)", "refs/tags/v1.0.0")
+	if err != nil || raw != fmt.Sprintf("%d -0530", date.Unix()) {
+		t.Errorf("expected the tagger date with its offset, got %q, %v", raw, err)
+	}
+	tags, err := repo.GetTags()
+	if err != nil || len(tags) != 1 || tags[0].Annotation == nil {
+		t.Fatalf("expected an annotated tag, got %v, %v", tags, err)
+	}
+	got := tags[0].Annotation.TaggerDate
+	if _, offset := got.Zone(); !got.Equal(date) || offset != -(5*60+30)*60 {
+		t.Errorf("expected tagger date %v, got %v", date, got)
+	}
+}
+
+func TestCommandRepositoryGetCommitsBetween(t *testing.T) {
+	repo, dirPath := newTestRepository(t)
+
+	hashes := make([]string, 0)
+	for _, name := range []string{"a", "b", "c"} {
+		filePath := filepath.Join(dirPath, name, "file.txt")
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filePath, []byte(name+"\n"), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if _, err := repo.AddFilePath(filePath); err != nil {
+			t.Fatalf("AddFilePath() error = %v", err)
+		}
+		if _, err := repo.CreateCommit("feat: " + name); err != nil {
+			t.Fatalf("CreateCommit() error = %v", err)
+		}
+		head, _ := repo.GetLastCommit()
+		hashes = append(hashes, head)
+	}
+
+	commits, err := repo.GetCommitsBetween("", hashes[1], ".")
+	if err != nil {
+		t.Fatalf("GetCommitsBetween() error = %v", err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "feat: b" || commits[1].Subject != "feat: a" {
+		t.Errorf("expected the commits up to b, newest first, got %v", commits)
+	}
+
+	commits, err = repo.GetCommitsBetween(hashes[0], hashes[2], filepath.Join(dirPath, "b"))
+	if err != nil {
+		t.Fatalf("GetCommitsBetween() error = %v", err)
+	}
+	if len(commits) != 1 || commits[0].Subject != "feat: b" {
+		t.Errorf("expected only the commit touching b, got %v", commits)
+	}
+
+	commits, err = repo.GetCommitsBetween("", "", dirPath, ExcludePathspec(filepath.Join(dirPath, "b")))
+	if err != nil {
+		t.Fatalf("GetCommitsBetween() error = %v", err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "feat: c" || commits[1].Subject != "feat: a" {
+		t.Errorf("expected the commits not touching b, got %v", commits)
+	}
+
+	commits, err = repo.GetCommitsInRange(hashes[0] + "..HEAD")
+	if err != nil {
+		t.Fatalf("GetCommitsInRange() error = %v", err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "feat: c" {
+		t.Errorf("expected the commits after a, got %v", commits)
+	}
+
+	outputPath := filepath.Join(dirPath, "output")
+	if _, err := repo.GetCommitsInRange("--output=" + outputPath); err == nil {
+		t.Errorf("expected a range starting with a dash to be rejected")
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Errorf("expected git not to write %s", outputPath)
+	}
+}
+
+func TestCommandRepositoryGetFileAt(t *testing.T) {
+	repo, dirPath := newTestRepository(t)
+
+	filePath := filepath.Join(dirPath, "app", ".version.json")
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	hashes := make([]string, 0)
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		if err := os.WriteFile(filePath, []byte(`{"version": "`+version+`"}`+"\n"), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if _, err := repo.AddFilePath(filePath); err != nil {
+			t.Fatalf("AddFilePath() error = %v", err)
+		}
+		if _, err := repo.CreateCommit("bump: " + version); err != nil {
+			t.Fatalf("CreateCommit() error = %v", err)
+		}
+		head, _ := repo.GetLastCommit()
+		hashes = append(hashes, head)
+	}
+
+	for i, expected := range []string{`{"version": "1.0.0"}` + "\n", `{"version": "1.1.0"}` + "\n"} {
+		for _, path := range []string{filePath, filepath.Join("app", ".version.json")} {
+			content, err := repo.GetFileAt(hashes[i], path)
+			if err != nil {
+				t.Fatalf("GetFileAt() error = %v", err)
+			}
+			if string(content) != expected {
+				t.Errorf("expected %q at %s, got %q", expected, hashes[i], content)
+			}
+		}
+	}
+
+	if _, err := repo.GetFileAt(hashes[0], "missing.json"); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestCommandRepositoryRestoreIndexEntry(t *testing.T) {
+	repo, dirPath := newTestRepository(t)
+
+	filePath := filepath.Join(dirPath, "app", "file.txt")
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, content := range []string{"committed\n", "staged\n"} {
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if _, err := repo.AddFilePath(filePath); err != nil {
+			t.Fatalf("AddFilePath() error = %v", err)
+		}
+		if content == "committed\n" {
+			if _, err := repo.CreateCommit("chore: init"); err != nil {
+				t.Fatalf("CreateCommit() error = %v", err)
+			}
+		}
+	}
+
+	entry, err := repo.GetIndexEntry(filePath)
+	if err != nil || entry == nil || entry.Path != "app/file.txt" {
+		t.Fatalf("expected the entry of app/file.txt, got %+v, %v", entry, err)
+	}
+	newPath := filepath.Join(dirPath, "new.txt")
+	if err := os.WriteFile(newPath, []byte("new\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if newEntry, err := repo.GetIndexEntry(newPath); err != nil || newEntry != nil {
+		t.Fatalf("expected no entry for new.txt, got %+v, %v", newEntry, err)
+	}
+
+	if err := os.WriteFile(filePath, []byte("bumped\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	for _, path := range []string{filePath, newPath} {
+		if _, err := repo.AddFilePath(path); err != nil {
+			t.Fatalf("AddFilePath() error = %v", err)
+		}
+	}
+
+	if _, err := repo.RestoreIndexEntry(filePath, entry); err != nil {
+		t.Fatalf("RestoreIndexEntry() error = %v", err)
+	}
+	if _, err := repo.RestoreIndexEntry(newPath, nil); err != nil {
+		t.Fatalf("RestoreIndexEntry() error = %v", err)
+	}
+
+	staged, err := repo.run("show", ":app/file.txt")
+	if err != nil || staged != "staged" {
+		t.Errorf("expected the staged content back in the index, got %q, %v", staged, err)
+	}
+	files, err := repo.GetStagedFiles()
+	if err != nil {
+		t.Fatalf("GetStagedFiles() error = %v", err)
+	}
+	if len(files) != 1 || files[0] != filepath.Join("app", "file.txt") {
+		t.Errorf("expected only app/file.txt staged, got %v", files)
+	}
+}
+
+func TestCommandRepositoryPathsWithOuterSpaces(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file names cannot end with a space on Windows")
+	}
+	repo, dirPath := newTestRepository(t)
+
+	for _, name := range []string{" leading.txt", "trailing.txt "} {
+		filePath := filepath.Join(dirPath, name)
+		if err := os.WriteFile(filePath, []byte("content\n"), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if _, err := repo.AddFilePath(filePath); err != nil {
+			t.Fatalf("AddFilePath() error = %v", err)
+		}
+	}
+
+	for _, name := range []string{" leading.txt", "trailing.txt "} {
+		files, err := repo.ListFiles(name)
+		if err != nil || len(files) != 1 || files[0] != name {
+			t.Errorf("expected ListFiles to return %q, got %q, %v", name, files, err)
+		}
+		entry, err := repo.GetIndexEntry(filepath.Join(dirPath, name))
+		if err != nil || entry == nil || entry.Path != name {
+			t.Errorf("expected the entry of %q, got %+v, %v", name, entry, err)
+		}
+	}
+	files, err := repo.GetStagedFiles()
+	if err != nil {
+		t.Fatalf("GetStagedFiles() error = %v", err)
+	}
+	if len(files) != 2 || files[0] != " leading.txt" || files[1] != "trailing.txt " {
+		t.Errorf("expected both files staged with their spaces, got %q", files)
+	}
+}
+
+func TestCommandRepositoryCreateCommitAt(t *testing.T) {
+	repo, dirPath := newTestRepository(t)
+
+	filePath := filepath.Join(dirPath, "file.txt")
+	if err := os.WriteFile(filePath, []byte("content\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := repo.AddFilePath(filePath); err != nil {
+		t.Fatalf("AddFilePath() error = %v", err)
+	}
+	date := time.Date(2024, 2, 1, 23, 59, 59, 0, time.UTC)
+	if _, err := repo.CreateCommitAt("chore: init", date); err != nil {
+		t.Fatalf("CreateCommitAt() error = %v", err)
+	}
+
+	commits, err := repo.GetCommits("", ".")
+	if err != nil {
+		t.Fatalf("GetCommits() error = %v", err)
+	}
+	if len(commits) != 1 || !commits[0].Date.Equal(date) {
+		t.Errorf("expected a commit dated %s, got %v", date, commits)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"**/*.md", "README.md", true},
+		{"**/*.md", "docs/api/README.md", true},
+		{"docs/*", "docs/api/README.md", false},
+		{"docs/**", "docs", true},
+		{"**", "", true},
+		{"api", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.expected {
+			t.Errorf("MatchGlob(%q, %q): expected %t, got %t", tt.pattern, tt.name, tt.expected, got)
+		}
+	}
+}
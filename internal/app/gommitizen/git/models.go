@@ -12,9 +12,31 @@ type Commit struct {
 	Hash    string    `json:"hash"`
 	Date    time.Time `json:"date"`
 	Subject string    `json:"subject"`
+	Message string    `json:"message"`
+}
+
+// Tag is a git tag, the commit it points to and the date of that commit. Annotation is set for annotated tags.
+type Tag struct {
+	Name       string         `json:"name"`
+	Commit     string         `json:"commit"`
+	Date       time.Time      `json:"date"`
+	Annotation *TagAnnotation `json:"annotation,omitempty"`
+}
+
+// TagAnnotation is the message and the tagger of an annotated tag.
+type TagAnnotation struct {
+	Message     string    `json:"message"`
+	TaggerName  string    `json:"tagger_name"`
+	TaggerEmail string    `json:"tagger_email"`
+	TaggerDate  time.Time `json:"tagger_date"`
+}
 
-	// TODO "message": "%s"
-	// Message string `json:"message"`
+// IndexEntry is the entry of a file in the index: its mode, the blob of its staged content and its path relative to
+// the root of the repository.
+type IndexEntry struct {
+	Mode string `json:"mode"`
+	Blob string `json:"blob"`
+	Path string `json:"path"`
 }
 
 func (c Commit) String() string {
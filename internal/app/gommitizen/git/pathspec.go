@@ -0,0 +1,93 @@
+package git
+
+import (
+	"path"
+	"strings"
+)
+
+// Magic words of pathspecs, https://git-scm.com/docs/gitglossary#Documentation/gitglossary.txt-aiddefpathspecapathspec
+const (
+	// PathspecExclude leaves out the files matched from those matched by the other pathspecs
+	PathspecExclude = "exclude"
+	// PathspecGlob matches with shell globs, where `*` stays in a directory and `**` spans directories
+	PathspecGlob = "glob"
+	// PathspecTop makes the path relative to the root of the repository instead of the working directory
+	PathspecTop = "top"
+)
+
+// Pathspec returns the pathspec of path with the given magic words, like `:(exclude,glob)docs/**`.
+func Pathspec(path string, magic ...string) string {
+	if len(magic) == 0 {
+		return path
+	}
+	return ":(" + strings.Join(magic, ",") + ")" + path
+}
+
+// ExcludePathspec returns the pathspec that leaves out the files under path from the files matched by the other
+// pathspecs.
+func ExcludePathspec(path string) string {
+	return Pathspec(path, PathspecExclude)
+}
+
+// parsePathspec returns the path of a pathspec built by Pathspec and its magic words.
+func parsePathspec(pathspec string) (string, map[string]bool) {
+	magic := make(map[string]bool)
+	if !strings.HasPrefix(pathspec, ":(") {
+		return pathspec, magic
+	}
+	end := strings.Index(pathspec, ")")
+	if end == -1 {
+		return pathspec, magic
+	}
+	for _, word := range strings.Split(pathspec[2:end], ",") {
+		magic[word] = true
+	}
+	return pathspec[end+1:], magic
+}
+
+// matchGlobPathspec tells whether filePath, or one of the directories it is in, matches a glob pathspec pattern.
+func matchGlobPathspec(pattern string, filePath string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	parts := strings.Split(strings.Trim(filePath, "/"), "/")
+	for i := 1; i <= len(parts); i++ {
+		if matchGlobParts(patternParts, parts[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchGlob tells whether name, a slash separated path, matches pattern. "**" matches any number of directories and
+// the other parts of pattern follow path.Match.
+func MatchGlob(pattern string, name string) bool {
+	return matchGlobParts(splitSlash(pattern), splitSlash(name))
+}
+
+func splitSlash(s string) []string {
+	s = strings.Trim(s, "/")
+	if len(s) == 0 {
+		return []string{}
+	}
+	return strings.Split(s, "/")
+}
+
+func matchGlobParts(pattern []string, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchGlobParts(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchGlobParts(pattern[1:], parts[1:])
+}
@@ -0,0 +1,41 @@
+package git
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// scpLikeURLRegex matches the scp-like syntax of ssh remotes, like `git@github.com:owner/repo.git`.
+var scpLikeURLRegex = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// WebURL returns the https URL of the web page of a repository from the URL of one of its remotes, or an empty string
+// when the remote is not hosted, like a local path. Credentials, ports and the .git suffix are dropped.
+func WebURL(remoteURL string) string {
+	remoteURL = strings.TrimSpace(remoteURL)
+
+	var host, path string
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return ""
+		}
+		switch u.Scheme {
+		case "http", "https", "ssh", "git", "git+ssh", "ssh+git":
+		default:
+			return ""
+		}
+		host, path = u.Hostname(), u.Path
+	} else if match := scpLikeURLRegex.FindStringSubmatch(remoteURL); match != nil && len(match[1]) > 1 {
+		// A single letter host is a Windows drive
+		host, path = match[1], match[2]
+	} else {
+		return ""
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if len(host) == 0 || len(path) == 0 {
+		return ""
+	}
+	return "https://" + host + "/" + path
+}
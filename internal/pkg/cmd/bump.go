@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -12,13 +14,13 @@ import (
 	"github.com/freepik-company/gommitizen/internal/app/gommitizen/changelog"
 	"github.com/freepik-company/gommitizen/internal/app/gommitizen/config"
 	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/diff"
 	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
 )
 
 func bumpCmd() *cobra.Command {
 	var validIncrements = []string{"MAJOR", "MINOR", "PATCH"}
-	var incrementType string
-	var createChangelog bool
+	var opts bumpOptions
 
 	cmd := &cobra.Command{
 		Use:   "bump",
@@ -34,139 +36,405 @@ func bumpCmd() *cobra.Command {
 			"gommitizen bump -c\n" +
 			"# This will bump the version of the projects and generate a changelog with the changes made since the last version.\n\n" +
 			"# If you want to bump the version of project to a major version, run:\n" +
-			"gommitizen bump -i MAJOR\n",
+			"gommitizen bump -i MAJOR\n\n" +
+			"# If you want to cut a release candidate (1.2.3 -> 1.3.0-rc.0, then 1.3.0-rc.1), run:\n" +
+			"gommitizen bump -p rc\n" +
+			"# A later bump without --prerelease promotes the release candidate (1.3.0-rc.1 -> 1.3.0).\n\n" +
+			"# If you want to see what a bump would do without changing anything, run:\n" +
+			"gommitizen bump --dry-run -c\n" +
+			"# This will print the new versions, the commits behind them, the diffs of the version files, the changelog\n" +
+			"# sections and the tags, without running hooks, writing files, committing or tagging.\n\n" +
+			"# If you want to bump without running the hooks of the projects and of the repository config, run:\n" +
+			"gommitizen bump --no-hooks\n",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			increment, _ := cmd.Flags().GetString("increment")
-			if increment == "" {
-				return nil
+			if err := validateFlagValue(cmd, "increment", validIncrements); err != nil {
+				return err
 			}
-			for _, valid := range validIncrements {
-				if increment == valid {
-					return nil
-				}
-			}
-			return fmt.Errorf(
-				"invalid increment value: %s, supported values: %s",
-				increment,
-				strings.Join(validIncrements, ", "),
-			)
+			return validateFlagValue(cmd, "prerelease", bumpmanager.ValidPrereleases)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			dirPath := cmd.Root().Flag(rootDirPathFlagName).Value.String()
-			bumpRun(dirPath, createChangelog, strings.ToLower(incrementType))
+			opts.incrementType = strings.ToLower(opts.incrementType)
+			bumpRun(git.NewRepository(dirPath), dirPath, opts)
 		},
 	}
 
-	cmd.Flags().BoolVarP(&createChangelog, "changelog", "c", false, "generate the changelog for the newest version")
-	cmd.Flags().StringVarP(&incrementType, "increment", "i", "", "manually specify the desired increment {MAJOR, MINOR, PATCH}")
+	cmd.Flags().BoolVarP(&opts.createChangelog, "changelog", "c", false, "generate the changelog for the newest version")
+	cmd.Flags().StringVarP(&opts.incrementType, "increment", "i", "", "manually specify the desired increment {MAJOR, MINOR, PATCH}")
+	cmd.Flags().StringVarP(&opts.prerelease, "prerelease", "p", "", "make a prerelease of the next version {alpha, beta, rc}")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "show the bump plan without changing files, running hooks, committing or tagging")
+	cmd.Flags().BoolVar(&opts.noHooks, "no-hooks", false, "bump without running any hook")
 
 	return cmd
 }
 
-func bumpRun(dirPath string, createChangelog bool, incrementType string) {
-	if incrementType != "" {
-		slog.Info(fmt.Sprintf("Bumping version with increment: %s", incrementType))
+type bumpOptions struct {
+	createChangelog bool
+	incrementType   string
+	prerelease      string
+	dryRun          bool
+	noHooks         bool
+}
+
+func bumpRun(repo git.Repository, dirPath string, opts bumpOptions) {
+	if opts.incrementType != "" {
+		slog.Info(fmt.Sprintf("Bumping version with increment: %s", opts.incrementType))
+	}
+
+	configVersions, err := findConfigVersions(repo, dirPath, "")
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	repositoryConfig, err := config.FindRepositoryConfig(dirPath)
+	if err != nil {
+		slog.Error(fmt.Sprintf("repository config: %v", err))
+		os.Exit(1)
+	}
+
+	if opts.noHooks {
+		slog.Info("Hooks are disabled")
+		repositoryConfig.DisableHooks()
 	}
 
-	configVersionPaths, err := config.FindConfigVersionFilePath(dirPath)
+	// Every project is bumped, committed and tagged, or the repository is left as it was
+	tx, err := bumpmanager.NewTransaction(repo)
 	if err != nil {
-		slog.Error(fmt.Sprintf("find config version paths: %v", err))
+		slog.Error(fmt.Sprintf("bump: %v", err))
 		os.Exit(1)
 	}
 
+	projects := make([]*bumpedProject, 0)
 	allModifiedFiles := make([]string, 0)
 	allTagVersions := make([]string, 0)
-	for _, configVersionPath := range configVersionPaths {
-		modifiedFiles, tagVersion, err := bumpByConfig(configVersionPath, createChangelog, incrementType)
+
+	// The on_failure hooks run for the projects bumped so far and for the bump, once the repository is restored
+	onFailure := func(failedStep string, err error) {
+		runFailureHooks(repositoryConfig, projects, failedStep, err)
+	}
+	if opts.dryRun {
+		onFailure = nil
+		for _, hook := range repositoryConfig.GetBumpHooks() {
+			slog.Info(fmt.Sprintf("Skipping bump hook %s: %s", hook.Name, hook.Command))
+		}
+	} else if err := repositoryConfig.RunBumpHook(config.HookPreBump, config.HookContext{}); err != nil {
+		failAndExit(tx, &bumpmanager.StepError{Step: "pre_bump bump hook", Err: err}, onFailure)
+	}
+
+	for _, configVersion := range configVersions {
+		project, err := bumpByConfig(tx, configVersion, opts)
 		if err != nil {
-			slog.Error(fmt.Sprintf("bump by config: %v", err))
+			projects = append(projects, project)
+			failAndExit(tx, err, onFailure)
+		}
+		if len(project.tag) > 0 {
+			projects = append(projects, project)
+			allModifiedFiles = append(allModifiedFiles, project.modifiedFiles...)
+			allTagVersions = append(allTagVersions, project.tag)
+		}
+	}
+
+	if opts.dryRun {
+		if len(allTagVersions) == 0 {
+			slog.Info("Dry run: nothing to commit")
+			return
+		}
+		message := bumpmanager.BumpCommitMessage(repositoryConfig.GetBumpMessage(), allTagVersions)
+		if rootChangelogPath, err := updateRootChangelog(tx, repositoryConfig, projects, time.Now(), true); err != nil {
+			slog.Error(fmt.Sprintf("bump failed, update root changelog: %v", err))
 			os.Exit(1)
+		} else if len(rootChangelogPath) > 0 {
+			allModifiedFiles = append(allModifiedFiles, rootChangelogPath)
 		}
-		if len(modifiedFiles) > 0 {
-			allModifiedFiles = append(allModifiedFiles, modifiedFiles...)
-			allTagVersions = append(allTagVersions, tagVersion)
+		if len(allModifiedFiles) > 0 {
+			slog.Info(fmt.Sprintf("Dry run: would commit %q", message))
 		}
+		slog.Info(fmt.Sprintf("Dry run: would create tags %s", strings.Join(allTagVersions, ", ")))
+		return
+	}
+
+	if len(allTagVersions) == 0 {
+		slog.Info("Nothing to commit")
+		return
+	}
+	message := bumpmanager.BumpCommitMessage(repositoryConfig.GetBumpMessage(), allTagVersions)
+	// The root changelog and the bump commit share the date of the bump
+	date := time.Now()
+
+	if rootChangelogPath, err := updateRootChangelog(tx, repositoryConfig, projects, date, false); err != nil {
+		failAndExit(tx, &bumpmanager.StepError{Step: "update root changelog", Err: err}, onFailure)
+	} else if len(rootChangelogPath) > 0 {
+		allModifiedFiles = append(allModifiedFiles, rootChangelogPath)
+	}
+
+	// The hooks that run once the projects are bumped know every tag of the bump
+	bumpCtx := config.HookContext{Tags: allTagVersions}
+	for _, project := range projects {
+		project.hookCtx.Tags = allTagVersions
+	}
+
+	if err := repositoryConfig.RunBumpHook(config.HookPostBump, bumpCtx); err != nil {
+		failAndExit(tx, &bumpmanager.StepError{Step: "post_bump bump hook", Err: err}, onFailure)
 	}
 
-	output, err := bumpmanager.BumpCommitAll(allModifiedFiles, allTagVersions)
+	hooks := bumpmanager.CommitHooks{
+		PreCommit: func() error {
+			return runCommitHooks(repositoryConfig, projects, config.HookPreCommit, (*config.ConfigVersion).RunPreCommit, bumpCtx)
+		},
+		PostTag: func() error {
+			return runCommitHooks(repositoryConfig, projects, config.HookPostTag, (*config.ConfigVersion).RunPostTag, bumpCtx)
+		},
+	}
+	output, err := bumpmanager.BumpCommitAll(tx, message, date, allModifiedFiles, allTagVersions, hooks)
 	if err != nil {
-		slog.Error(fmt.Sprintf("bump commit all: %v", err))
-		os.Exit(1)
+		failAndExit(tx, err, onFailure)
 	}
 
 	slog.Info(strings.Join(output, "\n"))
 }
 
-func bumpByConfig(configVersionPath string, createChangelog bool, incrementType string) ([]string, string, error) {
-	config, err := config.ReadConfigVersion(configVersionPath)
+// bumpedProject is the outcome of the bump of a project. A project that is not bumped has no tag.
+type bumpedProject struct {
+	config        *config.ConfigVersion
+	hookCtx       config.HookContext
+	release       changelog.ProjectRelease
+	modifiedFiles []string
+	tag           string
+}
+
+// updateRootChangelog adds the releases of the bumped projects to the root changelog of the repository, when it has
+// one, under date, and returns its path. With dryRun, the section is printed to stdout instead.
+func updateRootChangelog(tx *bumpmanager.Transaction, repositoryConfig *config.RepositoryConfig, projects []*bumpedProject, date time.Time, dryRun bool) (string, error) {
+	rootChangelogPath := repositoryConfig.GetRootChangelogPath()
+	if len(rootChangelogPath) == 0 {
+		return "", nil
+	}
+
+	releases := make([]changelog.ProjectRelease, 0, len(projects))
+	for _, project := range projects {
+		releases = append(releases, project.release)
+	}
+	links := resolveChangelogLinks(tx, repositoryConfig.GetChangelogLinks())
+
+	if dryRun {
+		section, err := changelog.RenderRoot(date, releases, links)
+		if err != nil {
+			return "", err
+		}
+		slog.Info(fmt.Sprintf("Root changelog section for %s:", rootChangelogPath))
+		fmt.Print(section)
+		return rootChangelogPath, nil
+	}
+
+	if err := tx.Track(rootChangelogPath); err != nil {
+		return "", err
+	}
+	slog.Info(fmt.Sprintf("Updating root changelog %s", rootChangelogPath))
+	return rootChangelogPath, changelog.ApplyRoot(rootChangelogPath, date, releases, links)
+}
+
+// runCommitHooks runs a hook point around the commit and the tags: the hooks of each project, then the bump hooks.
+func runCommitHooks(
+	repositoryConfig *config.RepositoryConfig,
+	projects []*bumpedProject,
+	name string,
+	run func(*config.ConfigVersion, config.HookContext) error,
+	bumpCtx config.HookContext,
+) error {
+	for _, project := range projects {
+		if err := run(project.config, project.hookCtx); err != nil {
+			return &bumpmanager.StepError{Project: project.config.GetDirPath(), Step: name + " hook", Err: err}
+		}
+	}
+	if err := repositoryConfig.RunBumpHook(name, bumpCtx); err != nil {
+		return &bumpmanager.StepError{Step: name + " bump hook", Err: err}
+	}
+	return nil
+}
+
+// failAndExit reports the step that broke the bump, restores the repository unless the bump was already released,
+// calls onFailure, when set, and exits.
+func failAndExit(tx *bumpmanager.Transaction, err error, onFailure func(failedStep string, err error)) {
+	slog.Error(fmt.Sprintf("bump failed, %v", err))
+
+	failedStep := err.Error()
+	var stepErr *bumpmanager.StepError
+	if errors.As(err, &stepErr) {
+		failedStep = stepErr.Step
+	}
+
+	if stepErr != nil && stepErr.Released {
+		// The tags may already be pushed, deleting them would leave the remote ahead of the repository
+		slog.Error("The bump commit and tags are kept, finish or revert the release manually")
+	} else {
+		slog.Info("Rolling back the bump...")
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			slog.Error(fmt.Sprintf("rollback incomplete, check the repository manually:\n%v", rollbackErr))
+		} else {
+			slog.Info("Rollback done, the repository is back to its state before the bump")
+		}
+	}
+
+	if onFailure != nil {
+		onFailure(failedStep, err)
+	}
+	os.Exit(1)
+}
+
+// runFailureHooks runs the on_failure hooks of the projects and of the bump. They cannot stop the bump from failing,
+// so their errors are only reported.
+func runFailureHooks(repositoryConfig *config.RepositoryConfig, projects []*bumpedProject, failedStep string, err error) {
+	for _, project := range projects {
+		hookCtx := project.hookCtx
+		hookCtx.FailedStep, hookCtx.Error = failedStep, err.Error()
+		if hookErr := project.config.RunOnFailure(hookCtx); hookErr != nil {
+			slog.Error(fmt.Sprintf("project %s: %v", project.config.GetDirPath(), hookErr))
+		}
+	}
+
+	bumpCtx := config.HookContext{FailedStep: failedStep, Error: err.Error()}
+	if hookErr := repositoryConfig.RunBumpHook(config.HookOnFailure, bumpCtx); hookErr != nil {
+		slog.Error(hookErr.Error())
+	}
+}
+
+// bumpByConfig bumps the project of a config version and runs its hooks up to the changelog ones.
+func bumpByConfig(tx *bumpmanager.Transaction, config *config.ConfigVersion, opts bumpOptions) (*bumpedProject, error) {
+	project := &bumpedProject{config: config, modifiedFiles: []string{}}
+	if opts.noHooks {
+		config.DisableHooks()
+	}
+
+	// Projects asking for it always update their changelog on bump
+	opts.createChangelog = opts.createChangelog || config.UpdateChangelogOnBump
+
+	stepError := func(step string, err error) error {
+		return &bumpmanager.StepError{Project: config.GetDirPath(), Step: step, Err: err}
+	}
+
+	err := config.ResolveVersion(tx)
 	if err != nil {
-		slog.Info(fmt.Sprintf("Skipping file: %s, %v", configVersionPath, err))
-		return []string{}, "", nil
+		return project, stepError("resolve version", err)
 	}
 
 	modifiedFiles := make([]string, 0)
-	gitTag := config.GetGitTag()
+	gitTag := ""
 
 	slog.Info(fmt.Sprintf("Running bump in project %s", config.GetDirPath()))
 
-	gitCommits, err := git.GetCommits(config.Commit, config.GetDirPath())
-	cvCommits := conventionalcommits.ReadConventionalCommits(gitCommits)
+	cvCommits, err := conventionalcommits.GetConventionalCommits(tx, config.GetStartCommit(opts.prerelease), config.GetPathspecs(), config.GetChangeTypes())
 	if err != nil {
-		return []string{}, "", fmt.Errorf("commit messages: %s", err)
+		return project, stepError("read commits", err)
 	}
+	incrementType := opts.incrementType
 	if incrementType == "" {
 		incrementType = conventionalcommits.DetermineIncrementType(cvCommits)
 	}
 
 	// If the file has been modified, update the version
 	if incrementType != "none" {
-		// Running pre-bump scripts
-		err = config.RunPreBump()
+		newVersion, newVersionStr, err := bumpmanager.IncrementVersion(config.Version, incrementType, opts.prerelease)
 		if err != nil {
-			return []string{}, "", fmt.Errorf("pre bump scripts: %s", err)
+			return project, stepError("increment version", err)
+		}
+		project.release = changelog.ProjectRelease{
+			Release: changelog.Release{
+				Version:         newVersion,
+				PreviousVersion: config.Version,
+				Tag:             config.GetGitTagForVersion(newVersion),
+				PreviousTag:     previousReleaseTag(tx, config, newVersion),
+				Commits:         cvCommits,
+			},
+			Name:        config.Alias,
+			Path:        config.GetRelativeDirPath(),
+			ChangeTypes: config.GetChangeTypes(),
 		}
 
-		newVersion, newVersionStr, err := bumpmanager.IncrementVersion(config.Version, incrementType)
+		lastCommit, err := tx.GetLastCommit()
 		if err != nil {
-			return []string{}, "", fmt.Errorf("increment version: %s", err)
+			return project, stepError("last commit", err)
 		}
 
-		lastCommit, err := git.GetLastCommit()
+		if opts.dryRun {
+			project.modifiedFiles, project.tag, err = planBumpByConfig(tx, config, cvCommits, newVersion, newVersionStr, lastCommit, opts)
+			if err != nil {
+				return project, stepError("plan", err)
+			}
+			return project, nil
+		}
+
+		// Save the files the bump writes before anything touches them, so a failure can restore them
+		changes, err := config.PlanVersion(newVersion, lastCommit)
 		if err != nil {
-			return []string{}, "", fmt.Errorf("last commit: %s", err)
+			return project, stepError("update version", err)
+		}
+		trackedFiles := make([]string, 0)
+		for _, change := range changes {
+			trackedFiles = append(trackedFiles, change.FilePath)
+		}
+		if opts.createChangelog {
+			for _, format := range config.GetChangelogFormats() {
+				trackedFiles = append(trackedFiles, changelog.GetFormatFilePath(config.GetDirPath(), format))
+			}
+		}
+		for _, filePath := range trackedFiles {
+			if err := tx.Track(filePath); err != nil {
+				return project, stepError("track files", err)
+			}
+		}
+
+		// The hooks are given the changelog of the first format
+		changelogFilePath := ""
+		if opts.createChangelog {
+			changelogFilePath = changelog.GetFormatFilePath(config.GetDirPath(), config.GetChangelogFormats()[0])
+		}
+		hookCtx := config.NewHookContext(newVersion, incrementType, changelogFilePath)
+		project.hookCtx = hookCtx
+
+		// Running pre-bump scripts
+		err = config.RunPreBump(hookCtx)
+		if err != nil {
+			return project, stepError("pre_bump hook", err)
 		}
 
 		slog.Info(fmt.Sprintf("%s change, %s -> %s", newVersionStr, config.Version, newVersion))
 
 		modifiedFiles, err = config.UpdateVersion(newVersion, lastCommit)
 		if err != nil {
-			return []string{}, "", fmt.Errorf("update version: %s", err)
+			return project, stepError("update version", err)
 		}
 
 		// Running post-bump scripts
-		err = config.RunPostBump()
+		err = config.RunPostBump(hookCtx)
 		if err != nil {
-			return []string{}, "", fmt.Errorf("post bump scripts: %s", err)
+			return project, stepError("post_bump hook", err)
 		}
 
-		if createChangelog {
+		if opts.createChangelog {
 			// Running pre-changelog scripts
-			err = config.RunPreChangelog()
+			err = config.RunPreChangelog(hookCtx)
 			if err != nil {
-				return []string{}, "", fmt.Errorf("pre changelog scripts: %s", err)
+				return project, stepError("pre_changelog hook", err)
 			}
 
 			slog.Info("Generating changelog...")
-			changelogFilePath, err := changelog.Apply(config.GetDirPath(), config.Version, cvCommits)
-			if err != nil {
-				return []string{}, "", fmt.Errorf("update changelog: %s", err)
+			changelogOpts := newChangelogOptions(tx, config)
+			changelogOpts.PreviousVersion, changelogOpts.Tag = hookCtx.PreviousVersion, config.GetGitTag()
+			changelogOpts.PreviousTag = previousReleaseTag(tx, config, config.Version)
+			for _, format := range config.GetChangelogFormats() {
+				changelogOpts.Format = format
+				changelogFilePath, err := changelog.Apply(config.GetDirPath(), config.Version, cvCommits, config.GetChangeTypes(), changelogOpts)
+				if err != nil {
+					return project, stepError("update changelog", err)
+				}
+				modifiedFiles = append(modifiedFiles, changelogFilePath)
 			}
-			modifiedFiles = append(modifiedFiles, changelogFilePath)
 
 			// Running post-changelog scripts
-			err = config.RunPostChangelog()
+			err = config.RunPostChangelog(hookCtx)
 			if err != nil {
-				return []string{}, "", fmt.Errorf("post changelog scripts: %s", err)
+				return project, stepError("post_changelog hook", err)
 			}
 		}
 
@@ -188,5 +456,91 @@ func bumpByConfig(configVersionPath string, createChangelog bool, incrementType
 		slog.Info(fmt.Sprintf("bump skipped in %s", config.GetDirPath()))
 	}
 	slog.Info("---")
+	project.modifiedFiles, project.tag = modifiedFiles, gitTag
+	return project, nil
+}
+
+// planBumpByConfig prints what bumpByConfig would do for the project and returns the files it would modify and the
+// tag it would create. The diffs of the version files and the changelog sections are printed as they are to stdout, so
+// they can be piped. Nothing is written and no hook is run.
+func planBumpByConfig(
+	repo git.Repository,
+	config *config.ConfigVersion,
+	cvCommits []conventionalcommits.CommitData,
+	newVersion string,
+	newVersionStr string,
+	lastCommit string,
+	opts bumpOptions,
+) ([]string, string, error) {
+	modifiedFiles := make([]string, 0)
+
+	slog.Info(fmt.Sprintf("%s change, %s -> %s", newVersionStr, config.Version, newVersion))
+
+	slog.Info("Commit messages:")
+	for _, commit := range cvCommits {
+		slog.Info(fmt.Sprintf(" - %s", commit))
+	}
+
+	changes, err := config.PlanVersion(newVersion, lastCommit)
+	if err != nil {
+		return []string{}, "", fmt.Errorf("plan version: %s", err)
+	}
+	slog.Info("Version files:")
+	for _, change := range changes {
+		if change.Matches > 0 {
+			slog.Info(fmt.Sprintf("%d version(s) to replace in %s", change.Matches, change.FilePath))
+		}
+		if d := diff.Unified(change.FilePath, change.FilePath, change.Before, change.After); len(d) > 0 {
+			if isTerminal(os.Stdout) {
+				d = diff.Colorize(d)
+			}
+			fmt.Print(d)
+		}
+		modifiedFiles = append(modifiedFiles, change.FilePath)
+	}
+
+	if opts.createChangelog {
+		changelogOpts := newChangelogOptions(repo, config)
+		changelogOpts.PreviousVersion, changelogOpts.Tag = config.Version, config.GetGitTagForVersion(newVersion)
+		changelogOpts.PreviousTag = previousReleaseTag(repo, config, newVersion)
+		for _, format := range config.GetChangelogFormats() {
+			changelogOpts.Format = format
+			changelogFilePath := changelog.GetFormatFilePath(config.GetDirPath(), format)
+			section, err := changelog.Render(newVersion, cvCommits, config.GetChangeTypes(), changelogOpts)
+			if err != nil {
+				return []string{}, "", fmt.Errorf("render changelog: %s", err)
+			}
+			slog.Info(fmt.Sprintf("Changelog section for %s:", changelogFilePath))
+			fmt.Print(section)
+			modifiedFiles = append(modifiedFiles, changelogFilePath)
+		}
+	}
+
+	for _, hook := range config.GetHooks() {
+		slog.Info(fmt.Sprintf("Skipping hook %s: %s", hook.Name, hook.Command))
+	}
+
+	gitTag := config.GetGitTagForVersion(newVersion)
+	slog.Info("New tags: " + gitTag)
+	slog.Info("---")
+
 	return modifiedFiles, gitTag, nil
 }
+
+func validateFlagValue(cmd *cobra.Command, flagName string, validValues []string) error {
+	value, _ := cmd.Flags().GetString(flagName)
+	if value == "" {
+		return nil
+	}
+	for _, valid := range validValues {
+		if value == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"invalid %s value: %s, supported values: %s",
+		flagName,
+		value,
+		strings.Join(validValues, ", "),
+	)
+}
@@ -0,0 +1,350 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/bumpmanager"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/config"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+func TestBumpByConfigWithFakeRepository(t *testing.T) {
+	dirPath := t.TempDir()
+	repo := git.NewFakeRepository()
+	first := repo.AddCommit("chore: init", dirPath)
+
+	cfg := config.NewConfigVersion(dirPath, "1.2.3", first, "app")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	repo.AddCommit("feat: add endpoint", filepath.Join(dirPath, "main.go"))
+	repo.AddCommit("fix: elsewhere", "other/main.go")
+
+	bumped, err := bumpByConfig(newTestTransaction(t, repo), readTestConfigVersion(t, cfg.GetFilePath()), bumpOptions{})
+	if err != nil {
+		t.Fatalf("bumpByConfig() error = %v", err)
+	}
+	if bumped.tag != "1.3.0+app" {
+		t.Errorf("expected tag 1.3.0+app, got %s", bumped.tag)
+	}
+	if len(bumped.modifiedFiles) != 1 || bumped.modifiedFiles[0] != cfg.GetFilePath() {
+		t.Errorf("expected only %s modified, got %v", cfg.GetFilePath(), bumped.modifiedFiles)
+	}
+
+	updated, err := config.ReadConfigVersion(cfg.GetFilePath())
+	if err != nil {
+		t.Fatalf("ReadConfigVersion() error = %v", err)
+	}
+	last, _ := repo.GetLastCommit()
+	if updated.Version != "1.3.0" || updated.Commit != last {
+		t.Errorf("expected version 1.3.0 at %s, got %s at %s", last, updated.Version, updated.Commit)
+	}
+}
+
+func TestBumpByConfigExcludesNestedProjects(t *testing.T) {
+	dirPath := t.TempDir()
+	repo := git.NewFakeRepository()
+	first := repo.AddCommit("chore: init", dirPath)
+
+	cfg := config.NewConfigVersion(dirPath, "1.2.3", first, "api")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	nested := config.NewConfigVersion(filepath.Join(dirPath, "client"), "0.1.0", first, "client")
+	if err := os.MkdirAll(nested.GetDirPath(), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := nested.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	repo.WorkDir = dirPath
+	repo.AddCommit("chore: add projects", cfg.GetFilePath(), nested.GetFilePath())
+	repo.AddCommit("feat(client): add call", filepath.Join(dirPath, "client", "client.go"))
+	repo.AddCommit("fix: handle nil", filepath.Join(dirPath, "main.go"))
+
+	configVersions, err := config.FindConfigVersions(repo, dirPath, "api")
+	if err != nil || len(configVersions) != 1 {
+		t.Fatalf("FindConfigVersions() expected the api project, got %v, error = %v", configVersions, err)
+	}
+	bumped, err := bumpByConfig(newTestTransaction(t, repo), configVersions[0], bumpOptions{})
+	if err != nil {
+		t.Fatalf("bumpByConfig() error = %v", err)
+	}
+	if bumped.tag != "1.2.4+api" {
+		t.Errorf("expected the feat of the nested project to be left out and tag 1.2.4+api, got %s", bumped.tag)
+	}
+
+	cfg.Version, cfg.Commit, cfg.IncludeNestedProjects = "1.2.3", first, true
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	bumped, err = bumpByConfig(newTestTransaction(t, repo), readTestConfigVersion(t, cfg.GetFilePath()), bumpOptions{})
+	if err != nil {
+		t.Fatalf("bumpByConfig() error = %v", err)
+	}
+	if bumped.tag != "1.3.0+api" {
+		t.Errorf("expected the feat of the nested project to count with include_nested_projects, got %s", bumped.tag)
+	}
+}
+
+func TestBumpByConfigWatchesPaths(t *testing.T) {
+	dirPath := t.TempDir()
+	repo := git.NewFakeRepository()
+	first := repo.AddCommit("chore: init", dirPath)
+
+	cfg := config.NewConfigVersion(dirPath, "1.2.3", first, "api")
+	cfg.Paths, cfg.IgnorePaths = []string{"/proto"}, []string{"docs"}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	repo.AddCommit("feat: document the api", filepath.Join(dirPath, "docs", "api.md"))
+	repo.AddCommit("fix: rename a field", "proto/api.proto")
+
+	bumped, err := bumpByConfig(newTestTransaction(t, repo), readTestConfigVersion(t, cfg.GetFilePath()), bumpOptions{})
+	if err != nil {
+		t.Fatalf("bumpByConfig() error = %v", err)
+	}
+	if bumped.tag != "1.2.4+api" {
+		t.Errorf("expected the fix of proto to count and the docs to be left out, got %s", bumped.tag)
+	}
+}
+
+func TestBumpByConfigPrereleaseFlow(t *testing.T) {
+	dirPath := t.TempDir()
+	repo := git.NewFakeRepository()
+	first := repo.AddCommit("chore: init", dirPath)
+
+	cfg := config.NewConfigVersion(dirPath, "1.2.3", first, "app")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	steps := []struct {
+		commit     string
+		prerelease string
+		want       string
+	}{
+		{"feat: add endpoint", "rc", "1.3.0-rc.0"},
+		{"fix: endpoint typo", "rc", "1.3.0-rc.1"},
+		{"", "", "1.3.0"},
+	}
+
+	for _, step := range steps {
+		if len(step.commit) > 0 {
+			repo.AddCommit(step.commit, filepath.Join(dirPath, "main.go"))
+		}
+
+		bumped, err := bumpByConfig(newTestTransaction(t, repo), readTestConfigVersion(t, cfg.GetFilePath()), bumpOptions{prerelease: step.prerelease})
+		if err != nil {
+			t.Fatalf("bumpByConfig() error = %v", err)
+		}
+		if bumped.tag != step.want+"+app" {
+			t.Errorf("expected tag %s+app, got %s", step.want, bumped.tag)
+		}
+		repo.AddCommit("bump: new version "+bumped.tag, cfg.GetFilePath())
+	}
+
+	updated, err := config.ReadConfigVersion(cfg.GetFilePath())
+	if err != nil {
+		t.Fatalf("ReadConfigVersion() error = %v", err)
+	}
+	if updated.ReleaseCommit != "" {
+		t.Errorf("expected release commit to be cleared after the final release, got %s", updated.ReleaseCommit)
+	}
+}
+
+func TestBumpByConfigDryRun(t *testing.T) {
+	dirPath := t.TempDir()
+	repo := git.NewFakeRepository()
+	first := repo.AddCommit("chore: init", dirPath)
+
+	cfg := config.NewConfigVersion(dirPath, "1.2.3", first, "app")
+	cfg.Hooks = &config.HookTypes{PreBump: config.HookCommands{"touch " + filepath.Join(dirPath, "hook-ran")}}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	before, err := os.ReadFile(cfg.GetFilePath())
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	repo.AddCommit("fix: handle nil", filepath.Join(dirPath, "main.go"))
+
+	bumped, err := bumpByConfig(newTestTransaction(t, repo), readTestConfigVersion(t, cfg.GetFilePath()), bumpOptions{createChangelog: true, dryRun: true})
+	if err != nil {
+		t.Fatalf("bumpByConfig() error = %v", err)
+	}
+	if bumped.tag != "1.2.4+app" {
+		t.Errorf("expected tag 1.2.4+app, got %s", bumped.tag)
+	}
+	if len(bumped.modifiedFiles) != 2 {
+		t.Errorf("expected version and changelog files in the plan, got %v", bumped.modifiedFiles)
+	}
+
+	after, err := os.ReadFile(cfg.GetFilePath())
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(before) != string(after) {
+		t.Errorf("expected %s to be untouched", cfg.GetFilePath())
+	}
+	for _, name := range []string{"CHANGELOG.md", "hook-ran"} {
+		if _, err := os.Stat(filepath.Join(dirPath, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to exist", name)
+		}
+	}
+}
+
+func readTestConfigVersion(t *testing.T, filePath string) *config.ConfigVersion {
+	t.Helper()
+	configVersion, err := config.ReadConfigVersion(filePath)
+	if err != nil {
+		t.Fatalf("ReadConfigVersion() error = %v", err)
+	}
+	return configVersion
+}
+
+func newTestTransaction(t *testing.T, repo git.Repository) *bumpmanager.Transaction {
+	t.Helper()
+	tx, err := bumpmanager.NewTransaction(repo)
+	if err != nil {
+		t.Fatalf("NewTransaction() error = %v", err)
+	}
+	return tx
+}
+
+func TestBumpByConfigVersionFromTags(t *testing.T) {
+	dirPath := t.TempDir()
+	repo := git.NewFakeRepository()
+	first := repo.AddCommit("chore: init", dirPath)
+
+	cfg := config.NewConfigVersion(dirPath, "", "", "app")
+	cfg.VersionSource = config.VersionSourceTag
+	cfg.TagFormat = "{alias}-v{version}"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	before, _ := os.ReadFile(cfg.GetFilePath())
+
+	// Without tags, the project starts at 0.0.0 from the first commit
+	repo.AddCommit("feat: first feature", filepath.Join(dirPath, "main.go"))
+	bumped, err := bumpByConfig(newTestTransaction(t, repo), readTestConfigVersion(t, cfg.GetFilePath()), bumpOptions{prerelease: "rc"})
+	if err != nil {
+		t.Fatalf("bumpByConfig() error = %v", err)
+	}
+	if bumped.tag != "app-v0.1.0-rc.0" {
+		t.Errorf("expected tag app-v0.1.0-rc.0, got %s", bumped.tag)
+	}
+	repo.Tags[bumped.tag], _ = repo.GetLastCommit()
+
+	// The latest tag gives the version, the prerelease is promoted with the commits since the first commit
+	bumped, err = bumpByConfig(newTestTransaction(t, repo), readTestConfigVersion(t, cfg.GetFilePath()), bumpOptions{})
+	if err != nil {
+		t.Fatalf("bumpByConfig() error = %v", err)
+	}
+	if bumped.tag != "app-v0.1.0" {
+		t.Errorf("expected tag app-v0.1.0, got %s", bumped.tag)
+	}
+	repo.Tags[bumped.tag], _ = repo.GetLastCommit()
+	repo.Tags["other-v9.0.0"] = first
+
+	repo.AddCommit("fix: a bug", filepath.Join(dirPath, "main.go"))
+	bumped, err = bumpByConfig(newTestTransaction(t, repo), readTestConfigVersion(t, cfg.GetFilePath()), bumpOptions{})
+	if err != nil {
+		t.Fatalf("bumpByConfig() error = %v", err)
+	}
+	if bumped.tag != "app-v0.1.1" || len(bumped.modifiedFiles) != 0 {
+		t.Errorf("expected only tag app-v0.1.1, got %s and files %v", bumped.tag, bumped.modifiedFiles)
+	}
+
+	after, _ := os.ReadFile(cfg.GetFilePath())
+	if string(before) != string(after) {
+		t.Errorf("expected %s to be untouched, got\n%s", cfg.GetFilePath(), string(after))
+	}
+}
+
+func TestUpdateRootChangelog(t *testing.T) {
+	root := t.TempDir()
+	repo := git.NewFakeRepository()
+	first := repo.AddCommit("chore: init", root)
+
+	repositoryConfigPath := filepath.Join(root, ".gommitizen.yaml")
+	if err := os.WriteFile(repositoryConfigPath, []byte("root_changelog: RELEASES.md\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	repositoryConfig, err := config.ReadRepositoryConfig(repositoryConfigPath)
+	if err != nil {
+		t.Fatalf("ReadRepositoryConfig() error = %v", err)
+	}
+
+	projects := make([]*bumpedProject, 0)
+	for _, alias := range []string{"api", "web"} {
+		dirPath := filepath.Join(root, alias)
+		cfg := config.NewConfigVersion(dirPath, "1.0.0", first, alias)
+		if err := os.MkdirAll(dirPath, 0755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := cfg.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		repo.AddCommit("fix: "+alias+" bug", filepath.Join(dirPath, "main.go"))
+
+		bumped, err := bumpByConfig(newTestTransaction(t, repo), readTestConfigVersion(t, cfg.GetFilePath()), bumpOptions{})
+		if err != nil {
+			t.Fatalf("bumpByConfig() error = %v", err)
+		}
+		projects = append(projects, bumped)
+	}
+
+	tx := newTestTransaction(t, repo)
+	date := time.Date(2024, 2, 1, 23, 59, 59, 0, time.UTC)
+	rootChangelogPath, err := updateRootChangelog(tx, repositoryConfig, projects, date, false)
+	if err != nil {
+		t.Fatalf("updateRootChangelog() error = %v", err)
+	}
+	if rootChangelogPath != filepath.Join(root, "RELEASES.md") {
+		t.Errorf("expected the root changelog next to the repository config, got %s", rootChangelogPath)
+	}
+
+	content, err := os.ReadFile(rootChangelogPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, part := range []string{"2024-02-01", "## api 1.0.0 -> 1.0.1\n", "- api bug (#", "## web 1.0.0 -> 1.0.1\n", "- web bug (#"} {
+		if !strings.Contains(string(content), part) {
+			t.Errorf("expected %q in the root changelog, got:\n%s", part, content)
+		}
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if _, err := os.Stat(rootChangelogPath); !os.IsNotExist(err) {
+		t.Errorf("expected the rollback to remove the root changelog")
+	}
+}
+
+func TestBumpRunNothingToBump(t *testing.T) {
+	dirPath := t.TempDir()
+	repo := git.NewFakeRepository()
+	first := repo.AddCommit("chore: init", dirPath)
+
+	cfg := config.NewConfigVersion(dirPath, "1.2.3", first, "app")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	repo.WorkDir = dirPath
+	repo.AddCommit("chore: tidy", filepath.Join(dirPath, "main.go"), cfg.GetFilePath())
+	commits := len(repo.Commits)
+
+	for _, dryRun := range []bool{true, false} {
+		bumpRun(repo, dirPath, bumpOptions{dryRun: dryRun})
+	}
+
+	if len(repo.Commits) != commits || len(repo.Tags) != 0 {
+		t.Errorf("expected no commit nor tag, got %d commits and tags %v", len(repo.Commits), repo.Tags)
+	}
+}
@@ -0,0 +1,378 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/spf13/cobra"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/changelog"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/config"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+func changelogCmd() *cobra.Command {
+	var opts changelogOptions
+
+	cmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Generate the changelog of the projects",
+		Long: `Generate a changelog section of each project from its conventional commits, without bumping. By default the
+section lists the changes made since the last bump under "Unreleased". With --to, it lists the changes of a release,
+from the previous release or from the version given with --from. With --rebuild, the whole changelog is generated again
+from the git history, a section per release.`,
+		Example: "# To add the unreleased changes of every project to their CHANGELOG.md, run:\n" +
+			"gommitizen changelog\n" +
+			"# Running it again replaces the Unreleased section instead of adding another one.\n\n" +
+			"# To keep the Unreleased section under the title of the changelog, run:\n" +
+			"gommitizen changelog --incremental\n\n" +
+			"# To print the release notes of version 1.3.0 of a project, run:\n" +
+			"gommitizen changelog --alias api --to 1.3.0 --stdout\n\n" +
+			"# To print the changes between two versions, run:\n" +
+			"gommitizen changelog --alias api --from 1.1.0 --to 1.3.0 --stdout\n\n" +
+			"# To regenerate the changelog of a project from its release tags, run:\n" +
+			"gommitizen changelog --alias api --rebuild\n\n" +
+			"# To print the release notes of version 1.3.0 as JSON, run:\n" +
+			"gommitizen changelog --alias api --to 1.3.0 --format json --stdout\n",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.unreleased && len(opts.to) > 0 {
+				return fmt.Errorf("--unreleased and --to cannot be used together")
+			}
+			if opts.rebuild && (opts.unreleased || opts.incremental || len(opts.from) > 0 || len(opts.to) > 0) {
+				return fmt.Errorf("--rebuild cannot be used with --unreleased, --from, --to or --incremental")
+			}
+			return changelog.ValidateFormats(opts.formats)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			dirPath := cmd.Root().Flag(rootDirPathFlagName).Value.String()
+			changelogRun(git.NewRepository(dirPath), dirPath, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.alias, "alias", "a", "", "a alias to look for a project to generate its changelog")
+	cmd.Flags().BoolVar(&opts.unreleased, "unreleased", false, "list the changes since the last bump, the default without --to")
+	cmd.Flags().StringVar(&opts.from, "from", "", "the version the changes are listed from, excluded")
+	cmd.Flags().StringVar(&opts.to, "to", "", "the version the changes are listed up to, included")
+	cmd.Flags().BoolVar(&opts.stdout, "stdout", false, "print the changelog instead of writing it to CHANGELOG.md")
+	cmd.Flags().BoolVar(&opts.incremental, "incremental", false, "insert the section under the title of CHANGELOG.md instead of at the top")
+	cmd.Flags().BoolVar(&opts.rebuild, "rebuild", false, "generate the whole changelog again with a section per release, keeping only the title of CHANGELOG.md")
+	cmd.Flags().StringSliceVar(&opts.formats, "format", nil, "the changelog formats to write instead of the changelog_formats of the projects: markdown, keepachangelog, asciidoc or json")
+
+	return cmd
+}
+
+type changelogOptions struct {
+	alias       string
+	unreleased  bool
+	from        string
+	to          string
+	stdout      bool
+	incremental bool
+	rebuild     bool
+	formats     []string
+}
+
+// changelogSection is the part of the history of a project a changelog section is made of.
+type changelogSection struct {
+	title           string
+	previousVersion string
+	tag             string
+	previousTag     string
+	fromCommit      string
+	toCommit        string
+	date            time.Time
+}
+
+func changelogRun(repo git.Repository, dirPath string, opts changelogOptions) {
+	configVersions, err := findConfigVersions(repo, dirPath, opts.alias)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	if len(configVersions) == 0 {
+		slog.Info("No projects found")
+		os.Exit(0)
+	}
+	if opts.stdout {
+		if err := validateStdoutFormats(configVersions, opts.formats); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	for _, configVersion := range configVersions {
+		if err := configVersion.ResolveVersion(repo); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+
+		formats := changelogFormats(configVersion, opts.formats)
+
+		if opts.rebuild {
+			for _, format := range formats {
+				content, changelogFilePath, releases, err := rebuildChangelog(repo, configVersion, format, opts.stdout)
+				if err != nil {
+					slog.Error(fmt.Sprintf("project %s: %v", configVersion.GetDirPath(), err))
+					os.Exit(1)
+				}
+
+				if opts.stdout {
+					fmt.Print(content)
+				} else {
+					slog.Info(fmt.Sprintf("Rebuilt %s with %d releases", changelogFilePath, releases))
+				}
+			}
+			continue
+		}
+
+		section, err := resolveChangelogSection(repo, configVersion, opts.from, opts.to)
+		if err != nil {
+			slog.Error(fmt.Sprintf("project %s: %v", configVersion.GetDirPath(), err))
+			os.Exit(1)
+		}
+
+		for _, format := range formats {
+			content, changelogFilePath, err := writeChangelog(repo, configVersion, section, format, opts)
+			if err != nil {
+				slog.Error(fmt.Sprintf("project %s: %v", configVersion.GetDirPath(), err))
+				os.Exit(1)
+			}
+
+			if opts.stdout {
+				fmt.Print(content)
+			} else {
+				slog.Info(fmt.Sprintf("Updated %s with the %s section", changelogFilePath, section.title))
+			}
+		}
+	}
+}
+
+// changelogFormats returns the formats given with --format, or else the changelog formats of the project.
+func changelogFormats(configVersion *config.ConfigVersion, formats []string) []string {
+	if len(formats) == 0 {
+		return configVersion.GetChangelogFormats()
+	}
+	return formats
+}
+
+// validateStdoutFormats makes sure --stdout prints a JSON changelog as the only document of the output, not along with
+// the changelog of another project or in another format.
+func validateStdoutFormats(configVersions []*config.ConfigVersion, formats []string) error {
+	outputs, json := 0, false
+	for _, configVersion := range configVersions {
+		for _, format := range changelogFormats(configVersion, formats) {
+			outputs++
+			json = json || format == changelog.FormatJSON
+		}
+	}
+	if json && outputs > 1 {
+		return fmt.Errorf("--stdout prints a JSON changelog alone, select a project with --alias and a format with --format")
+	}
+	return nil
+}
+
+// writeChangelog renders the changelog section of the project in a format and writes it to the changelog file of the
+// format, unless opts ask for stdout. It returns the section and the changelog file written, if any.
+func writeChangelog(repo git.Repository, configVersion *config.ConfigVersion, section changelogSection, format string, opts changelogOptions) (string, string, error) {
+	cvCommits, err := conventionalcommits.GetConventionalCommitsBetween(
+		repo, section.fromCommit, section.toCommit, configVersion.GetPathspecs(), configVersion.GetChangeTypes(),
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("read commits: %v", err)
+	}
+
+	changelogOpts := newChangelogOptions(repo, configVersion)
+	changelogOpts.Date, changelogOpts.Incremental, changelogOpts.Format = section.date, opts.incremental, format
+	changelogOpts.PreviousVersion, changelogOpts.Tag, changelogOpts.PreviousTag = section.previousVersion, section.tag, section.previousTag
+	if opts.stdout {
+		content, err := changelog.Render(section.title, cvCommits, configVersion.GetChangeTypes(), changelogOpts)
+		return content, "", err
+	}
+
+	changelogFilePath, err := changelog.Apply(configVersion.GetDirPath(), section.title, cvCommits, configVersion.GetChangeTypes(), changelogOpts)
+	return "", changelogFilePath, err
+}
+
+// rebuildChangelog renders a changelog section in a format for every release of the project, each listing the commits
+// since the release it follows as the bump would have, and writes them to the changelog file of the format unless
+// stdout is set. It returns the sections, the changelog file written, if any, and the number of releases.
+func rebuildChangelog(repo git.Repository, configVersion *config.ConfigVersion, format string, stdout bool) (string, string, int, error) {
+	releases, err := resolveReleaseHistory(repo, configVersion)
+	if err != nil {
+		return "", "", 0, err
+	}
+	pathspecs := configVersion.GetPathspecs()
+
+	changelogReleases := make([]changelog.Release, 0, len(releases))
+	for i, release := range releases {
+		fromCommit, previousVersion, previousTag := "", "", ""
+		if previous := previousRelease(releases, i); previous != nil {
+			fromCommit, previousVersion, previousTag = previous.Commit, previous.Version, previous.Tag
+		}
+
+		cvCommits, err := conventionalcommits.GetConventionalCommitsBetween(
+			repo, fromCommit, release.Commit, pathspecs, configVersion.GetChangeTypes(),
+		)
+		if err != nil {
+			return "", "", 0, fmt.Errorf("read commits of %s: %v", release.Version, err)
+		}
+		changelogReleases = append(changelogReleases, changelog.Release{
+			Version:         release.Version,
+			PreviousVersion: previousVersion,
+			Tag:             release.Tag,
+			PreviousTag:     previousTag,
+			Date:            release.Date,
+			Commits:         cvCommits,
+		})
+	}
+
+	changelogOpts := newChangelogOptions(repo, configVersion)
+	changelogOpts.Format = format
+	if stdout {
+		content, err := changelog.RenderReleases(changelogReleases, configVersion.GetChangeTypes(), changelogOpts)
+		return content, "", len(releases), err
+	}
+
+	changelogFilePath, err := changelog.Rebuild(configVersion.GetDirPath(), changelogReleases, configVersion.GetChangeTypes(), changelogOpts)
+	return "", changelogFilePath, len(releases), err
+}
+
+// resolveReleaseHistory returns the releases of the project from its release tags or, when it has none, from the
+// commits that bumped the version of its config version file.
+func resolveReleaseHistory(repo git.Repository, configVersion *config.ConfigVersion) ([]config.Release, error) {
+	tags, err := repo.GetTags()
+	if err != nil {
+		return nil, fmt.Errorf("read tags: %v", err)
+	}
+	if releases := configVersion.GetReleases(tags); len(releases) > 0 {
+		return releases, nil
+	}
+
+	releases, err := configVersion.GetReleasesFromBumps(repo)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("no release tags with format %s nor version bumps of %s found",
+			configVersion.GetTagFormat(), configVersion.GetFilePath())
+	}
+	return releases, nil
+}
+
+// resolveChangelogSection finds the commits of the changelog section asked for. Without to, the section holds the
+// changes made since from, or since the last bump, and is titled Unreleased. With to, it holds the changes of that
+// release since from, or since the previous release. A final release is listed from the previous final release, so its
+// section folds in the changes of its prereleases, as the bump does.
+func resolveChangelogSection(repo git.Repository, configVersion *config.ConfigVersion, from string, to string) (changelogSection, error) {
+	tags, err := repo.GetTags()
+	if err != nil {
+		return changelogSection{}, fmt.Errorf("read tags: %v", err)
+	}
+	releases := configVersion.GetReleases(tags)
+
+	section := changelogSection{title: changelog.Unreleased, previousVersion: configVersion.Version, fromCommit: configVersion.Commit}
+	if index, err := findRelease(releases, configVersion.Version); err == nil {
+		section.previousTag = releases[index].Tag
+	}
+	if len(to) > 0 {
+		index, err := findRelease(releases, to)
+		if err != nil {
+			return changelogSection{}, err
+		}
+		release := releases[index]
+		section = changelogSection{title: release.Version, tag: release.Tag, toCommit: release.Commit, date: release.Date}
+
+		if previous := previousRelease(releases, index); previous != nil {
+			section.previousVersion, section.previousTag, section.fromCommit = previous.Version, previous.Tag, previous.Commit
+		}
+	}
+
+	if len(from) > 0 {
+		index, err := findRelease(releases, from)
+		if err != nil {
+			return changelogSection{}, err
+		}
+		section.previousVersion, section.previousTag, section.fromCommit = releases[index].Version, releases[index].Tag, releases[index].Commit
+	}
+
+	return section, nil
+}
+
+// newChangelogOptions returns the options every changelog section of a project is rendered with: its changelog
+// template and the links to the forge of the repository, derived from the origin remote.
+func newChangelogOptions(repo git.Repository, configVersion *config.ConfigVersion) changelog.Options {
+	return changelog.Options{
+		TemplatePath: configVersion.GetChangelogTemplatePath(),
+		Links:        resolveChangelogLinks(repo, configVersion.GetChangelogLinks()),
+	}
+}
+
+// resolveChangelogLinks returns the links to the forge of the repository, derived from its origin remote for those
+// links does not set.
+func resolveChangelogLinks(repo git.Repository, links changelog.Links) changelog.Links {
+	remoteURL, err := repo.GetRemoteURL("origin")
+	if err != nil {
+		slog.Debug(fmt.Sprintf("no origin remote to link the changelog to: %v", err))
+	}
+	return changelog.ResolveLinks(remoteURL, links)
+}
+
+// previousReleaseTag returns the tag of the release a new version of the project is listed from, picked as
+// previousRelease does, or an empty string when it has none.
+func previousReleaseTag(repo git.Repository, configVersion *config.ConfigVersion, version string) string {
+	newVersion, err := semver.NewVersion(version)
+	if err != nil {
+		return ""
+	}
+	tags, err := repo.GetTags()
+	if err != nil {
+		slog.Debug(fmt.Sprintf("no previous tag to link the changelog to: %v", err))
+		return ""
+	}
+
+	releases := make([]config.Release, 0)
+	for _, release := range configVersion.GetReleases(tags) {
+		if v, err := semver.NewVersion(release.Version); err == nil && v.LessThan(newVersion) {
+			releases = append(releases, release)
+		}
+	}
+	releases = append(releases, config.Release{Version: newVersion.String()})
+
+	if previous := previousRelease(releases, len(releases)-1); previous != nil {
+		return previous.Tag
+	}
+	return ""
+}
+
+// findRelease returns the index of the release of a version, given with or without a leading v.
+func findRelease(releases []config.Release, version string) (int, error) {
+	wanted, err := semver.NewVersion(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return -1, fmt.Errorf("invalid version %s: %v", version, err)
+	}
+
+	for i, release := range releases {
+		if release.Version == wanted.String() {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no release tag found for version %s", version)
+}
+
+// previousRelease returns the release the release at index is listed from: the one before it, skipping prereleases
+// when it is a final release. It returns nil for the first release.
+func previousRelease(releases []config.Release, index int) *config.Release {
+	final := !strings.Contains(releases[index].Version, "-")
+	for i := index - 1; i >= 0; i-- {
+		if final && strings.Contains(releases[i].Version, "-") {
+			continue
+		}
+		return &releases[i]
+	}
+	return nil
+}
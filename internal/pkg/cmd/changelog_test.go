@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/changelog"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/config"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+func TestResolveChangelogSection(t *testing.T) {
+	dirPath := t.TempDir()
+	repo := git.NewFakeRepository()
+	first := repo.AddCommit("chore: init", dirPath)
+	repo.AddCommit("feat: one", filepath.Join(dirPath, "main.go"))
+	repo.Tags["1.0.0+app"], _ = repo.GetLastCommit()
+	repo.AddCommit("feat: two", filepath.Join(dirPath, "main.go"))
+	repo.Tags["1.1.0-rc.0+app"], _ = repo.GetLastCommit()
+	repo.AddCommit("fix: three", filepath.Join(dirPath, "main.go"))
+	repo.Tags["1.1.0+app"], _ = repo.GetLastCommit()
+	repo.Tags["2.0.0+other"] = first
+	repo.AddCommit("fix: four", filepath.Join(dirPath, "main.go"))
+
+	cfg := config.NewConfigVersion(dirPath, "1.1.0", repo.Tags["1.1.0+app"], "app")
+
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		title    string
+		previous string
+		subjects []string
+	}{
+		{"unreleased", "", "", changelog.Unreleased, "1.1.0", []string{"fix: four"}},
+		{"final release folds its prereleases", "", "1.1.0", "1.1.0", "1.0.0", []string{"fix: three", "feat: two"}},
+		{"prerelease", "", "v1.1.0-rc.0", "1.1.0-rc.0", "1.0.0", []string{"feat: two"}},
+		{"first release", "", "1.0.0", "1.0.0", "", []string{"feat: one", "chore: init"}},
+		{"between versions", "1.0.0", "1.1.0-rc.0", "1.1.0-rc.0", "1.0.0", []string{"feat: two"}},
+		{"since a version", "1.1.0-rc.0", "", changelog.Unreleased, "1.1.0-rc.0", []string{"fix: four", "fix: three"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			section, err := resolveChangelogSection(repo, cfg, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("resolveChangelogSection() error = %v", err)
+			}
+			if section.title != tt.title {
+				t.Errorf("expected title %s, got %s", tt.title, section.title)
+			}
+			if section.previousVersion != tt.previous {
+				t.Errorf("expected previous version %s, got %s", tt.previous, section.previousVersion)
+			}
+			if len(tt.to) > 0 && section.tag != tt.title+"+app" {
+				t.Errorf("expected tag %s+app, got %s", tt.title, section.tag)
+			}
+
+			commits, err := repo.GetCommitsBetween(section.fromCommit, section.toCommit, dirPath)
+			if err != nil {
+				t.Fatalf("GetCommitsBetween() error = %v", err)
+			}
+			subjects := make([]string, 0)
+			for _, commit := range commits {
+				subjects = append(subjects, commit.Subject)
+			}
+			if !reflect.DeepEqual(subjects, tt.subjects) {
+				t.Errorf("expected %v, got %v", tt.subjects, subjects)
+			}
+		})
+	}
+
+	if _, err := resolveChangelogSection(repo, cfg, "", "3.0.0"); err == nil {
+		t.Errorf("expected an error for a version without release tag")
+	}
+}
+
+func TestRebuildChangelog(t *testing.T) {
+	dirPath := t.TempDir()
+	repo := git.NewFakeRepository()
+	repo.AddCommit("feat: one", filepath.Join(dirPath, "main.go"))
+	repo.Tags["1.0.0+app"], _ = repo.GetLastCommit()
+	repo.AddCommit("feat: two", filepath.Join(dirPath, "main.go"))
+	repo.Tags["1.1.0-rc.0+app"], _ = repo.GetLastCommit()
+	repo.AddCommit("fix: three", filepath.Join(dirPath, "main.go"))
+	repo.Tags["1.1.0+app"], _ = repo.GetLastCommit()
+	repo.AddCommit("fix: unreleased", filepath.Join(dirPath, "main.go"))
+
+	cfg := config.NewConfigVersion(dirPath, "1.1.0", repo.Tags["1.1.0+app"], "app")
+	if err := os.WriteFile(changelog.GetFormatFilePath(dirPath, changelog.FormatMarkdown), []byte("# Changelog\n\n# 0.1.0 (2020-01-01)\n- lost\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	_, changelogFilePath, releases, err := rebuildChangelog(repo, cfg, changelog.FormatMarkdown, false)
+	if err != nil {
+		t.Fatalf("rebuildChangelog() error = %v", err)
+	}
+	if releases != 3 {
+		t.Errorf("expected 3 releases, got %d", releases)
+	}
+
+	content, err := os.ReadFile(changelogFilePath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	expected := []string{"# Changelog\n\n# 1.1.0 (", "- two (#", "- three (#", "# 1.1.0-rc.0 (", "- two (#", "# 1.0.0 ("}
+	offset := 0
+	for _, part := range expected {
+		index := strings.Index(string(content[offset:]), part)
+		if index == -1 {
+			t.Fatalf("expected %q after offset %d in:\n%s", part, offset, content)
+		}
+		offset += index + len(part)
+	}
+	if strings.Contains(string(content), "unreleased") || strings.Contains(string(content), "0.1.0") {
+		t.Errorf("expected only the released sections under the title, got:\n%s", content)
+	}
+}
+
+func TestRebuildChangelogFromBumps(t *testing.T) {
+	dirPath := t.TempDir()
+	repo := git.NewFakeRepository()
+	cfg := config.NewConfigVersion(dirPath, "1.0.0", "", "app")
+	versionFile := func(version string) string {
+		return `{"version": "` + version + `", "commit": "", "alias": "app"}`
+	}
+
+	repo.AddFileCommit("chore: init", cfg.GetFilePath(), versionFile("1.0.0"))
+	repo.AddCommit("feat: one", filepath.Join(dirPath, "main.go"))
+	repo.AddFileCommit("bump: new version 1.1.0+app", cfg.GetFilePath(), versionFile("1.1.0"))
+	repo.AddCommit("fix: two", filepath.Join(dirPath, "main.go"))
+	repo.AddFileCommit("chore: edit settings", cfg.GetFilePath(), versionFile("1.1.0"))
+	bump := repo.AddFileCommit("bump: new version 1.1.1+app", cfg.GetFilePath(), versionFile("1.1.1"))
+
+	releases, err := resolveReleaseHistory(repo, cfg)
+	if err != nil {
+		t.Fatalf("resolveReleaseHistory() error = %v", err)
+	}
+	versions := make([]string, 0)
+	for _, release := range releases {
+		versions = append(versions, release.Version)
+	}
+	if !reflect.DeepEqual(versions, []string{"1.1.0", "1.1.1"}) {
+		t.Fatalf("expected releases 1.1.0 and 1.1.1, got %v", versions)
+	}
+	if releases[1].Commit != bump || releases[1].Tag != "1.1.1+app" {
+		t.Errorf("expected 1.1.1+app released at %s, got %s at %s", bump, releases[1].Tag, releases[1].Commit)
+	}
+
+	content, _, _, err := rebuildChangelog(repo, cfg, changelog.FormatMarkdown, true)
+	if err != nil {
+		t.Fatalf("rebuildChangelog() error = %v", err)
+	}
+	newest, oldest := strings.Index(content, "# 1.1.1 ("), strings.Index(content, "# 1.1.0 (")
+	if newest == -1 || oldest == -1 || newest > oldest || !strings.Contains(content[newest:oldest], "- two (#") {
+		t.Errorf("expected 1.1.1 with its fix above 1.1.0, got:\n%s", content)
+	}
+	if _, err := os.Stat(changelog.GetFormatFilePath(dirPath, changelog.FormatMarkdown)); !os.IsNotExist(err) {
+		t.Errorf("expected no changelog file to be written with stdout")
+	}
+}
+
+func TestValidateStdoutFormats(t *testing.T) {
+	api := config.NewConfigVersion(t.TempDir(), "1.0.0", "", "api")
+	web := config.NewConfigVersion(t.TempDir(), "1.0.0", "", "web")
+	web.ChangelogFormats = []string{changelog.FormatMarkdown, changelog.FormatJSON}
+
+	tests := []struct {
+		name           string
+		configVersions []*config.ConfigVersion
+		formats        []string
+		wantErr        bool
+	}{
+		{"one project in json", []*config.ConfigVersion{api}, []string{changelog.FormatJSON}, false},
+		{"several projects in markdown", []*config.ConfigVersion{api, web}, []string{changelog.FormatMarkdown}, false},
+		{"several projects in json", []*config.ConfigVersion{api, web}, []string{changelog.FormatJSON}, true},
+		{"json and another format", []*config.ConfigVersion{api}, []string{changelog.FormatJSON, changelog.FormatAsciiDoc}, true},
+		{"json in the project formats", []*config.ConfigVersion{web}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateStdoutFormats(tt.configVersions, tt.formats); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPreviousReleaseTag(t *testing.T) {
+	dirPath := t.TempDir()
+	repo := git.NewFakeRepository()
+	repo.AddCommit("feat: one", filepath.Join(dirPath, "main.go"))
+	repo.Tags["1.0.0+app"], _ = repo.GetLastCommit()
+	repo.AddCommit("feat: two", filepath.Join(dirPath, "main.go"))
+	repo.Tags["1.1.0-rc.0+app"], _ = repo.GetLastCommit()
+
+	cfg := config.NewConfigVersion(dirPath, "1.1.0-rc.0", repo.Tags["1.1.0-rc.0+app"], "app")
+
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"1.1.0-rc.1", "1.1.0-rc.0+app"},
+		{"1.1.0", "1.0.0+app"},
+		{"1.0.0", ""},
+	}
+	for _, tt := range tests {
+		if tag := previousReleaseTag(repo, cfg, tt.version); tag != tt.expected {
+			t.Errorf("expected previous tag of %s to be %q, got %q", tt.version, tt.expected, tag)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/config"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+const (
+	checkMessageFileFlagName = "message-file"
+	checkRevRangeFlagName    = "rev-range"
+)
+
+// scissorsLine marks the start of the diff git appends to the message file with `git commit --verbose`.
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+func checkCmd() *cobra.Command {
+	var messageFile, revisionRange string
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check that commit messages are conventional commits",
+		Long: `Check commit messages against the conventional commits specification, the change types and the check 
+rules of the repository config. Messages are parsed as bump parses them. The message is read from a file, from the 
+commits of a revision range or from the standard input. Every offending message is printed with the reasons and the 
+command fails.`,
+		Example: "# In a commit-msg hook, run:\n" +
+			"gommitizen check --message-file \"$1\"\n" +
+			"# In CI, to check the commits of a branch, run:\n" +
+			"gommitizen check --rev-range origin/main..HEAD\n" +
+			"# To check a message from the standard input, run:\n" +
+			"echo 'feat: add check command' | gommitizen check",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(messageFile) > 0 && len(revisionRange) > 0 {
+				return fmt.Errorf("--%s and --%s cannot be used together", checkMessageFileFlagName, checkRevRangeFlagName)
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			dirPath := cmd.Root().Flag(rootDirPathFlagName).Value.String()
+			checkRun(git.NewRepository(dirPath), dirPath, messageFile, revisionRange, os.Stdin)
+		},
+	}
+
+	cmd.Flags().StringVar(&messageFile, checkMessageFileFlagName, "", "check the message in a file, like the one given to the commit-msg hook")
+	cmd.Flags().StringVar(&revisionRange, checkRevRangeFlagName, "", "check the commits of a revision range, like origin/main..HEAD")
+
+	return cmd
+}
+
+// checkedMessage is a commit message to check, with the name it is reported by.
+type checkedMessage struct {
+	Name    string
+	Message string
+}
+
+func checkRun(repo git.Repository, dirPath string, messageFile string, revisionRange string, stdin io.Reader) {
+	repositoryConfig, err := config.FindRepositoryConfig(dirPath)
+	if err != nil {
+		slog.Error(fmt.Sprintf("repository config: %v", err))
+		os.Exit(1)
+	}
+
+	messages, err := readCheckedMessages(repo, messageFile, revisionRange, stdin)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	failures := checkMessages(messages, repositoryConfig.GetChangeTypes(), repositoryConfig.Check)
+	if failures > 0 {
+		slog.Error(fmt.Sprintf("%d of %d commit message(s) are not valid", failures, len(messages)))
+		os.Exit(1)
+	}
+	slog.Info(fmt.Sprintf("%d commit message(s) checked, all valid", len(messages)))
+}
+
+func readCheckedMessages(repo git.Repository, messageFile string, revisionRange string, stdin io.Reader) ([]checkedMessage, error) {
+	if len(revisionRange) > 0 {
+		commits, err := repo.GetCommitsInRange(revisionRange)
+		if err != nil {
+			return nil, fmt.Errorf("read commits of %s: %v", revisionRange, err)
+		}
+		messages := make([]checkedMessage, 0, len(commits))
+		// Oldest first, as they were written
+		for i := len(commits) - 1; i >= 0; i-- {
+			messages = append(messages, checkedMessage{Name: commits[i].AbbreviationHash(), Message: commits[i].Message})
+		}
+		return messages, nil
+	}
+
+	var data []byte
+	var err error
+	if len(messageFile) > 0 && messageFile != "-" {
+		data, err = os.ReadFile(messageFile)
+	} else {
+		messageFile = "stdin"
+		data, err = io.ReadAll(stdin)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read message: %v", err)
+	}
+	return []checkedMessage{{Name: messageFile, Message: cleanMessage(string(data))}}, nil
+}
+
+// cleanMessage removes from a message file what git removes before committing: the comment lines and the diff below
+// the scissors line.
+func cleanMessage(message string) string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(message, "\n") {
+		if strings.TrimRight(line, "\r") == scissorsLine {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// checkMessages prints every message that is not valid with the reasons and returns how many there are.
+func checkMessages(messages []checkedMessage, changeTypes conventionalcommits.ChangeTypes, rules conventionalcommits.CheckRules) int {
+	failures := 0
+	for _, message := range messages {
+		reasons := conventionalcommits.Check(message.Message, changeTypes, rules)
+		if len(reasons) == 0 {
+			continue
+		}
+		failures++
+
+		header := strings.SplitN(message.Message, "\n", 2)[0]
+		slog.Error(fmt.Sprintf("%s %q", message.Name, header))
+		for _, reason := range reasons {
+			slog.Error(fmt.Sprintf("  - %s", reason))
+		}
+	}
+	return failures
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+func TestReadCheckedMessagesRevisionRange(t *testing.T) {
+	repo := git.NewFakeRepository()
+	base := repo.AddCommit("chore: initial commit")
+	repo.AddCommit("feat: add check command")
+	repo.AddCommit("wip")
+
+	messages, err := readCheckedMessages(repo, "", base+"..", nil)
+	if err != nil {
+		t.Fatalf("readCheckedMessages() error = %v", err)
+	}
+	if len(messages) != 2 || messages[0].Message != "feat: add check command" || messages[1].Message != "wip" {
+		t.Fatalf("expected the two commits after base, oldest first, got %+v", messages)
+	}
+
+	failures := checkMessages(messages, conventionalcommits.DefaultChangeTypes(), conventionalcommits.CheckRules{})
+	if failures != 1 {
+		t.Errorf("expected 1 failure, got %d", failures)
+	}
+}
+
+func TestReadCheckedMessagesStdin(t *testing.T) {
+	stdin := strings.NewReader("fix: typo\n\n# Please enter the commit message\n" + scissorsLine + "\ndiff --git a/x b/x\n")
+
+	messages, err := readCheckedMessages(git.NewFakeRepository(), "", "", stdin)
+	if err != nil {
+		t.Fatalf("readCheckedMessages() error = %v", err)
+	}
+	if len(messages) != 1 || messages[0].Message != "fix: typo" {
+		t.Errorf("expected the cleaned message, got %+v", messages)
+	}
+}
@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/config"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+// retryMessageFileName is the file in the .git directory holding the message of the last failed commit.
+const retryMessageFileName = "GOMMITIZEN_MSG"
+
+type commitOptions struct {
+	message conventionalcommits.Message
+	retry   bool
+}
+
+func commitCmd() *cobra.Command {
+	var opts commitOptions
+
+	cmd := &cobra.Command{
+		Use:   "commit",
+		Short: "Create a conventional commit with the staged changes",
+		Long: `Build a conventional commit message and commit the staged changes with it. Without flags, it asks for 
+the type, scope, subject, body, breaking change and issue references; the scopes offered are the aliases of the 
+projects touched by the staged files. With --type and --subject, the message is built from the flags. The message is 
+checked like the check command does. When the commit fails, for example because of a pre-commit hook, the message is 
+kept and --retry commits it again.`,
+		Example: "# To build the message interactively, run:\n" +
+			"gommitizen commit\n" +
+			"# To commit from a script, run:\n" +
+			"gommitizen commit --type feat --scope api --subject 'add endpoint' --issue '#12'\n" +
+			"# To commit again with the message of the last failed commit, run:\n" +
+			"gommitizen commit --retry",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			messageFlags := []string{"type", "scope", "subject", "body", "breaking", "issue"}
+			if opts.retry {
+				for _, flagName := range messageFlags {
+					if cmd.Flags().Changed(flagName) {
+						return fmt.Errorf("--retry cannot be used with --%s", flagName)
+					}
+				}
+				return nil
+			}
+			if cmd.Flags().Changed("type") != cmd.Flags().Changed("subject") {
+				return fmt.Errorf("--type and --subject must be given together")
+			}
+			if !cmd.Flags().Changed("type") {
+				for _, flagName := range messageFlags {
+					if cmd.Flags().Changed(flagName) {
+						return fmt.Errorf("--%s needs --type and --subject", flagName)
+					}
+				}
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			dirPath := cmd.Root().Flag(rootDirPathFlagName).Value.String()
+			commitRun(git.NewRepository(dirPath), dirPath, opts, os.Stdin, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.message.Type, "type", "t", "", "type of the change, like feat or fix")
+	cmd.Flags().StringVarP(&opts.message.Scope, "scope", "s", "", "scope of the change")
+	cmd.Flags().StringVarP(&opts.message.Subject, "subject", "m", "", "short description of the change")
+	cmd.Flags().StringVarP(&opts.message.Body, "body", "b", "", "longer description of the change")
+	cmd.Flags().StringVar(&opts.message.Breaking, "breaking", "", "description of the breaking change, makes the commit a breaking change")
+	cmd.Flags().StringSliceVar(&opts.message.Issues, "issue", nil, "issue reference, can be repeated")
+	cmd.Flags().BoolVar(&opts.retry, "retry", false, "commit with the message of the last failed commit")
+
+	return cmd
+}
+
+func commitRun(repo git.Repository, dirPath string, opts commitOptions, in io.Reader, out io.Writer) {
+	message, err := prepareCommitMessage(repo, dirPath, opts, in, out)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if err := commitMessage(repo, message); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	slog.Info(fmt.Sprintf("Committed %q", strings.SplitN(message, "\n", 2)[0]))
+}
+
+// prepareCommitMessage returns the message to commit: the one of the last failed commit, the one built from the flags
+// or the one built from the answers to the prompts. The message must pass the check.
+func prepareCommitMessage(repo git.Repository, dirPath string, opts commitOptions, in io.Reader, out io.Writer) (string, error) {
+	if opts.retry {
+		filePath, err := retryMessageFilePath(repo)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", fmt.Errorf("no failed commit to retry")
+			}
+			return "", fmt.Errorf("read message to retry: %v", err)
+		}
+		return string(data), nil
+	}
+
+	repositoryConfig, err := config.FindRepositoryConfig(dirPath)
+	if err != nil {
+		return "", fmt.Errorf("repository config: %v", err)
+	}
+	changeTypes := repositoryConfig.GetChangeTypes()
+	rules := repositoryConfig.Check
+
+	message := opts.message
+	if len(message.Type) == 0 {
+		stagedFiles, err := repo.GetStagedFiles()
+		if err != nil {
+			return "", fmt.Errorf("staged files: %v", err)
+		}
+		if len(stagedFiles) == 0 {
+			return "", fmt.Errorf("nothing staged to commit")
+		}
+
+		scopes, err := scopeChoices(repo, dirPath, stagedFiles, rules)
+		if err != nil {
+			return "", err
+		}
+		message, err = askCommitMessage(newPrompter(in, out), changeTypes, scopes, rules)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	text := message.String()
+	if reasons := conventionalcommits.Check(text, changeTypes, rules); len(reasons) > 0 {
+		return "", fmt.Errorf("invalid commit message %q:\n  - %s", strings.SplitN(text, "\n", 2)[0], strings.Join(reasons, "\n  - "))
+	}
+	return text, nil
+}
+
+func askCommitMessage(p *prompter, changeTypes conventionalcommits.ChangeTypes, scopes []string, rules conventionalcommits.CheckRules) (conventionalcommits.Message, error) {
+	var message conventionalcommits.Message
+	var err error
+
+	// Breaking changes are asked for apart, the breaking changes type is still accepted by name
+	typeOptions := make([]string, 0, len(changeTypes))
+	for _, changeType := range changeTypes {
+		if changeType.CommonName == conventionalcommits.CommonNameBC {
+			continue
+		}
+		typeOptions = append(typeOptions, fmt.Sprintf("%s (%s)", changeType.Prefixes[0], changeType.CommonName))
+	}
+	message.Type, err = p.choose("Type of change", typeOptions, true, func(answer string) bool {
+		_, ok := changeTypes.Lookup(answer)
+		return ok
+	})
+	if err != nil {
+		return message, err
+	}
+
+	// Only the configured scopes are accepted when there are some
+	var anyScope func(string) bool
+	if len(rules.Scopes) == 0 {
+		anyScope = func(string) bool { return true }
+	}
+	message.Scope, err = p.choose("Scope (number, name or empty)", scopes, len(rules.Scopes) > 0, anyScope)
+	if err != nil {
+		return message, err
+	}
+
+	if message.Subject, err = p.ask("Short description", true); err != nil {
+		return message, err
+	}
+	if message.Body, err = p.askLines("Longer description"); err != nil {
+		return message, err
+	}
+	if message.Breaking, err = p.ask("Breaking change, empty if none", false); err != nil {
+		return message, err
+	}
+	issues, err := p.ask("Issue references separated by commas, empty if none", false)
+	if err != nil {
+		return message, err
+	}
+	if len(issues) > 0 {
+		message.Issues = strings.Split(issues, ",")
+	}
+
+	return message, nil
+}
+
+// scopeChoices returns the aliases of the projects touched by the staged files, followed by the scopes allowed by the
+// check rules. When the check rules list scopes, aliases not in the list are left out.
+func scopeChoices(repo git.Repository, dirPath string, stagedFiles []string, rules conventionalcommits.CheckRules) ([]string, error) {
+	configVersions, err := findConfigVersions(repo, dirPath, "")
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(scope string) {
+		if len(scope) > 0 && !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	for _, stagedFile := range stagedFiles {
+		var owner *config.ConfigVersion
+		ownerDepth := -1
+		for _, configVersion := range configVersions {
+			projectDir, err := filepath.Rel(dirPath, configVersion.GetDirPath())
+			if err != nil {
+				continue
+			}
+			projectDir = filepath.ToSlash(projectDir)
+			if projectDir != "." && stagedFile != projectDir && !strings.HasPrefix(stagedFile, projectDir+"/") {
+				continue
+			}
+			// The deepest project owns the file
+			if depth := len(projectDir); depth > ownerDepth {
+				owner, ownerDepth = configVersion, depth
+			}
+		}
+		if owner != nil && (len(rules.Scopes) == 0 || conventionalcommits.ContainsFold(rules.Scopes, owner.Alias)) {
+			add(owner.Alias)
+		}
+	}
+	for _, scope := range rules.Scopes {
+		add(scope)
+	}
+
+	return scopes, nil
+}
+
+// commitMessage commits the staged changes with message. The message is kept until the commit succeeds, so a failed
+// commit can be retried.
+func commitMessage(repo git.Repository, message string) error {
+	filePath, err := retryMessageFilePath(repo)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filePath, []byte(message), 0644); err != nil {
+		return fmt.Errorf("save message: %v", err)
+	}
+
+	if _, err := repo.CreateCommit(message); err != nil {
+		return fmt.Errorf("commit failed, fix the problem and run `gommitizen commit --retry`: %v", err)
+	}
+
+	if err := os.Remove(filePath); err != nil {
+		slog.Debug(fmt.Sprintf("remove %s: %v", filePath, err))
+	}
+	return nil
+}
+
+func retryMessageFilePath(repo git.Repository) (string, error) {
+	gitDir, err := repo.GetGitDir()
+	if err != nil {
+		return "", fmt.Errorf("git dir: %v", err)
+	}
+	return filepath.Join(gitDir, retryMessageFileName), nil
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/conventionalcommits"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+func newCommitTestRepository(t *testing.T) (*git.FakeRepository, string) {
+	t.Helper()
+	dirPath := t.TempDir()
+	repo := git.NewFakeRepository()
+	repo.GitDir = t.TempDir()
+	repo.WorkDir = dirPath
+	repo.AddCommit("chore: initial commit")
+
+	for _, project := range []struct{ dir, alias string }{{"api", "api"}, {"web", "frontend"}, {"web/admin", "admin"}} {
+		if err := os.MkdirAll(filepath.Join(dirPath, project.dir), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		data := `{"version": "1.0.0", "alias": "` + project.alias + `"}`
+		if err := os.WriteFile(filepath.Join(dirPath, project.dir, ".version.json"), []byte(data), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		repo.AddCommit("chore: add "+project.alias, filepath.Join(dirPath, project.dir, ".version.json"))
+	}
+	return repo, dirPath
+}
+
+func TestPrepareCommitMessageInteractive(t *testing.T) {
+	repo, dirPath := newCommitTestRepository(t)
+	repo.Staged = []string{"web/admin/main.go", "api/handler.go", "web/index.html"}
+
+	answers := strings.Join([]string{
+		"unknown", // not a type
+		"1",       // feat
+		"1",       // first scope
+		"",        // subject is required
+		"add login page",
+		"Users can log in:",
+		"  - with a password",
+		"",
+		"",
+		"#12, #13",
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	message, err := prepareCommitMessage(repo, dirPath, commitOptions{}, strings.NewReader(answers), &out)
+	if err != nil {
+		t.Fatalf("prepareCommitMessage() error = %v\n%s", err, out.String())
+	}
+
+	expected := "feat(admin): add login page\n\nUsers can log in:\n  - with a password\n\nRefs: #12, #13"
+	if message != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, message)
+	}
+	if !strings.Contains(out.String(), "1) admin\n  2) api\n  3) frontend\n") {
+		t.Errorf("expected the aliases of the touched projects as scopes, got:\n%s", out.String())
+	}
+}
+
+func TestScopeChoicesWithCheckRules(t *testing.T) {
+	repo, dirPath := newCommitTestRepository(t)
+
+	scopes, err := scopeChoices(repo, dirPath, []string{"api/main.go", "web/index.html"}, conventionalcommits.CheckRules{Scopes: []string{"api", "docs"}})
+	if err != nil {
+		t.Fatalf("scopeChoices() error = %v", err)
+	}
+	if !reflect.DeepEqual(scopes, []string{"api", "docs"}) {
+		t.Errorf("expected [api docs], got %v", scopes)
+	}
+}
+
+func TestCommitMessageRetry(t *testing.T) {
+	repo, dirPath := newCommitTestRepository(t)
+	opts := commitOptions{message: conventionalcommits.Message{Type: "fix", Subject: "handle nil"}}
+
+	message, err := prepareCommitMessage(repo, dirPath, opts, nil, nil)
+	if err != nil {
+		t.Fatalf("prepareCommitMessage() error = %v", err)
+	}
+
+	// Nothing is staged, so the commit fails and the message is kept
+	if err := commitMessage(repo, message); err == nil {
+		t.Fatalf("expected the commit to fail")
+	}
+
+	repo.Staged = []string{"api/handler.go"}
+	retried, err := prepareCommitMessage(repo, dirPath, commitOptions{retry: true}, nil, nil)
+	if err != nil {
+		t.Fatalf("prepareCommitMessage() with retry error = %v", err)
+	}
+	if retried != "fix: handle nil" {
+		t.Errorf("expected the failed message, got %q", retried)
+	}
+	if err := commitMessage(repo, retried); err != nil {
+		t.Fatalf("commitMessage() error = %v", err)
+	}
+
+	last := repo.Commits[len(repo.Commits)-1]
+	if last.Message != "fix: handle nil" {
+		t.Errorf("expected the commit to be created, got %q", last.Message)
+	}
+	if _, err := prepareCommitMessage(repo, dirPath, commitOptions{retry: true}, nil, nil); err == nil {
+		t.Errorf("expected no message to retry after a successful commit")
+	}
+}
+
+func TestPrepareCommitMessageInvalidFlags(t *testing.T) {
+	repo, dirPath := newCommitTestRepository(t)
+	opts := commitOptions{message: conventionalcommits.Message{Type: "wip", Subject: "stuff"}}
+
+	if _, err := prepareCommitMessage(repo, dirPath, opts, nil, nil); err == nil {
+		t.Errorf("expected an unknown type to be rejected")
+	}
+}
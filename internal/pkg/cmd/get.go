@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/freepik-company/gommitizen/internal/app/gommitizen/config"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
 )
 
 const (
@@ -28,7 +29,11 @@ information and all the information saved in the config file.`,
 			"# To show the version of the projects in plain format, run:\n" +
 			"gommitizen get version -o plain\n" +
 			"# or just:\n" +
-			"gommitizen get version\n",
+			"gommitizen get version\n" +
+			"# To show which projects are nested in others, run:\n" +
+			"gommitizen get tree\n" +
+			"# To show the paths whose commits bump each project, run:\n" +
+			"gommitizen get paths\n",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if output != "json" && output != "yaml" && output != "plain" {
 				return fmt.Errorf("invalid output format: %s, supported values: json, yaml, plain", output)
@@ -50,6 +55,8 @@ information and all the information saved in the config file.`,
 	cmd.AddCommand(getVersionCmd())
 	cmd.AddCommand(getAliasCmd())
 	cmd.AddCommand(getCommitCmd())
+	cmd.AddCommand(getTreeCmd())
+	cmd.AddCommand(getPathsCmd())
 
 	return cmd
 }
@@ -111,40 +118,69 @@ func getCommitCmd() *cobra.Command {
 	}
 }
 
+func getTreeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "tree",
+		Short: "Get the tree of the projects",
+		Long: `Get the projects of the repository as a tree, each project under the project whose directory holds it. The
+commits of a nested project are not counted for the projects above it, unless they set include_nested_projects.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			dirPath := cmd.Root().Flag(rootDirPathFlagName).Value.String()
+			alias := cmd.Parent().Flag(getAliasFlagName).Value.String()
+			output := cmd.Parent().Flag(getOutputFlagName).Value.String()
+			printProjects(dirPath, alias, output, func(configVersions []*config.ConfigVersion) (string, error) {
+				return config.PrintProjectTree(configVersions, output)
+			})
+		},
+	}
+}
+
+func getPathsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "paths",
+		Short: "Get the paths watched by the projects",
+		Long: `Get the paths whose commits count for each project, marked with +, and the paths left out of them, marked
+with -, together with the setting each one comes from: the project directory, paths, ignore_paths or a nested project.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			dirPath := cmd.Root().Flag(rootDirPathFlagName).Value.String()
+			alias := cmd.Parent().Flag(getAliasFlagName).Value.String()
+			output := cmd.Parent().Flag(getOutputFlagName).Value.String()
+			printProjects(dirPath, alias, output, func(configVersions []*config.ConfigVersion) (string, error) {
+				return config.PrintWatchedPaths(configVersions, output)
+			})
+		},
+	}
+}
+
 func projectsRun(dirPath string, alias string, output string, filter []string) {
-	var configVersionPaths []string
-	var err error
+	printProjects(dirPath, alias, output, func(configVersions []*config.ConfigVersion) (string, error) {
+		return config.PrintConfigVersions(configVersions, filter, output)
+	})
+}
 
-	if alias == "" {
-		configVersionPaths, err = config.FindConfigVersionFilePath(dirPath)
-		if err != nil {
-			slog.Error(fmt.Sprintf("finding config version file path: %v", err))
-			os.Exit(1)
-		}
-	} else {
-		configVersionPaths, err = config.FindConfigVersionFilePathByAlias(dirPath, alias)
-		if err != nil {
-			slog.Error(fmt.Sprintf("finding config version file path by alias: %v", err))
-			os.Exit(1)
-		}
+// printProjects prints the projects under dirPath, or the projects with the given alias, with their version resolved,
+// as print renders them.
+func printProjects(dirPath string, alias string, output string, print func([]*config.ConfigVersion) (string, error)) {
+	repo := git.NewRepository(dirPath)
+	configVersions, err := findConfigVersions(repo, dirPath, alias)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
 	}
 
-	if len(configVersionPaths) == 0 {
+	if len(configVersions) == 0 {
 		slog.Info("No projects found")
 		os.Exit(0)
 	}
 
-	var configVersions []*config.ConfigVersion
-	for _, configVersionPath := range configVersionPaths {
-		configVersionFile, err := config.ReadConfigVersion(configVersionPath)
-		if err != nil {
-			slog.Error(fmt.Sprintf("reading configVersionFile version: %v", err))
-			continue
+	for _, configVersion := range configVersions {
+		if err := configVersion.ResolveVersion(repo); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
 		}
-		configVersions = append(configVersions, configVersionFile)
 	}
 
-	str, err := config.PrintConfigVersions(configVersions, filter, output)
+	str, err := print(configVersions)
 	if err != nil {
 		slog.Error(fmt.Sprintf("printing config versions: %v", err))
 		os.Exit(1)
@@ -157,3 +193,13 @@ func projectsRun(dirPath string, alias string, output string, filter []string) {
 		slog.Info(str)
 	}
 }
+
+// findConfigVersions reads the config version of every project under dirPath, or only of the projects with the given
+// alias.
+func findConfigVersions(repo git.Repository, dirPath string, alias string) ([]*config.ConfigVersion, error) {
+	configVersions, err := config.FindConfigVersions(repo, dirPath, alias)
+	if err != nil {
+		return nil, fmt.Errorf("finding config versions: %v", err)
+	}
+	return configVersions, nil
+}
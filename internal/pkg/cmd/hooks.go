@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/config"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+const (
+	hooksOutputFlagName = "output"
+)
+
+func hooksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "Inspect the hooks a bump runs",
+		Long: `Inspect the hooks defined by the projects and by the repository config. Hooks are shell commands a bump
+runs with bash, so they should be reviewed like any other code of the repository.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := cmd.Help()
+			if err != nil {
+				return
+			}
+		},
+	}
+
+	cmd.AddCommand(hooksListCmd())
+
+	return cmd
+}
+
+func hooksListCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List every hook a bump would run",
+		Long: `List every hook command a bump would run, in the order it runs them for each project, together with the
+file that defines it. Hooks defined by projects outside the allowed_hook_paths of the repository config are listed as
+not allowed, a bump skips them.`,
+		Example: "# To review the hooks of the repository, run:\n" +
+			"gommitizen hooks list\n" +
+			"# To feed them to another tool, run:\n" +
+			"gommitizen hooks list -o json\n",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if output != "json" && output != "yaml" && output != "plain" {
+				return fmt.Errorf("invalid output format: %s, supported values: json, yaml, plain", output)
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			dirPath := cmd.Root().Flag(rootDirPathFlagName).Value.String()
+			hooksListRun(git.NewRepository(dirPath), dirPath, output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, hooksOutputFlagName, "o", "plain", "select the output format {json, yaml, plain}")
+
+	return cmd
+}
+
+func hooksListRun(repo git.Repository, dirPath string, output string) {
+	definitions, err := listHookDefinitions(repo, dirPath)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	str, err := printHookDefinitions(definitions, output)
+	if err != nil {
+		slog.Error(fmt.Sprintf("printing hooks: %v", err))
+		os.Exit(1)
+	}
+
+	// Print directly to stdout for structured formats to allow piping to tools like jq
+	if output == "json" || output == "yaml" {
+		fmt.Println(str)
+	} else {
+		slog.Info(str)
+	}
+}
+
+// listHookDefinitions returns the hooks run once per bump followed by the hooks of each project under dirPath.
+func listHookDefinitions(repo git.Repository, dirPath string) ([]config.HookDefinition, error) {
+	repositoryConfig, err := config.FindRepositoryConfig(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("repository config: %v", err)
+	}
+
+	configVersions, err := findConfigVersions(repo, dirPath, "")
+	if err != nil {
+		return nil, err
+	}
+
+	definitions := repositoryConfig.GetBumpHookDefinitions()
+	for _, configVersion := range configVersions {
+		definitions = append(definitions, configVersion.GetHookDefinitions()...)
+	}
+	return definitions, nil
+}
+
+func printHookDefinitions(definitions []config.HookDefinition, output string) (string, error) {
+	switch output {
+	case "json":
+		data, err := json.MarshalIndent(definitions, "", "  ")
+		return string(data), err
+	case "yaml":
+		data, err := yaml.Marshal(definitions)
+		return string(data), err
+	}
+
+	if len(definitions) == 0 {
+		return "No hooks defined", nil
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tHOOK\tCOMMAND\tDEFINED IN\tALLOWED")
+	for _, definition := range definitions {
+		project := definition.Project
+		if len(project) == 0 {
+			project = "(every bump)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", project, definition.Name, definition.Command, definition.DefinedIn, definition.Allowed)
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
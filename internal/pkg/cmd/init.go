@@ -29,7 +29,7 @@ the first commit of the project.`,
 			"# This will create a .version.json file in the given directory with the version 0.0.0.",
 		Run: func(cmd *cobra.Command, args []string) {
 			dirPath := cmd.Root().Flag(rootDirPathFlagName).Value.String()
-			initRun(dirPath, alias, updateChangelogOnBump)
+			initRun(git.NewRepository(dirPath), dirPath, alias, updateChangelogOnBump)
 		},
 	}
 
@@ -39,8 +39,8 @@ the first commit of the project.`,
 	return cmd
 }
 
-func initRun(dirPath, alias string, updateChangelogOnBump bool) {
-	commit, err := git.GetFirstCommit()
+func initRun(repo git.Repository, dirPath, alias string, updateChangelogOnBump bool) {
+	commit, err := repo.GetFirstCommit()
 	if err != nil {
 		slog.Error(fmt.Sprintf("first commit: %v", err))
 		os.Exit(1)
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// prompter asks questions line by line, reading the answers from in.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// readLine returns the next line of input without its line ending.
+func (p *prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		if err == io.EOF {
+			return "", fmt.Errorf("input closed before the message was complete")
+		}
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// ask returns the answer to a question, asking again while a required answer is empty.
+func (p *prompter) ask(question string, required bool) (string, error) {
+	for {
+		fmt.Fprintf(p.out, "%s: ", question)
+		line, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		answer := strings.TrimSpace(line)
+		if len(answer) > 0 || !required {
+			return answer, nil
+		}
+		fmt.Fprintln(p.out, "An answer is required.")
+	}
+}
+
+// askLines returns the lines answered until a blank one, keeping their indentation.
+func (p *prompter) askLines(question string) (string, error) {
+	fmt.Fprintf(p.out, "%s (end with an empty line):\n", question)
+	lines := make([]string, 0)
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if len(strings.TrimSpace(line)) == 0 {
+			return strings.Join(lines, "\n"), nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+// choose returns one of the options, picked by number or by name. valid accepts answers that are not in the options,
+// nil accepts none. An empty answer is returned when not required.
+func (p *prompter) choose(question string, options []string, required bool, valid func(string) bool) (string, error) {
+	for i, option := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, option)
+	}
+	for {
+		answer, err := p.ask(question, required)
+		if err != nil {
+			return "", err
+		}
+		if len(answer) == 0 {
+			return "", nil
+		}
+		if index, err := strconv.Atoi(answer); err == nil && index >= 1 && index <= len(options) {
+			return strings.Fields(options[index-1])[0], nil
+		}
+		for _, option := range options {
+			if answer == strings.Fields(option)[0] {
+				return answer, nil
+			}
+		}
+		if valid != nil && valid(answer) {
+			return answer, nil
+		}
+		fmt.Fprintf(p.out, "'%s' is not a valid choice.\n", answer)
+	}
+}
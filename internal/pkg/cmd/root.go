@@ -56,6 +56,11 @@ each project.`,
 	root.AddCommand(initCmd())
 	root.AddCommand(bumpCmd())
 	root.AddCommand(getCmd())
+	root.AddCommand(tagCmd())
+	root.AddCommand(checkCmd())
+	root.AddCommand(commitCmd())
+	root.AddCommand(hooksCmd())
+	root.AddCommand(changelogCmd())
 
 	return root
 }
@@ -90,3 +95,12 @@ func toAbsoluteDirPath(relativePath string) (string, error) {
 func isRelativeDirPath(path string) bool {
 	return !filepath.IsAbs(path)
 }
+
+// isTerminal tells whether f is a terminal, so output written to it can be colored.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/config"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+const (
+	tagAliasFlagName = "alias"
+)
+
+func tagCmd() *cobra.Command {
+	var alias string
+
+	cmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Manage the git tags of the projects",
+		Long: `Manage the git tags of the projects in the repository. Tags are named after the tag_format of each project,
+where {version}, {alias} and {path} are replaced by the version, the alias and the directory of the project.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := cmd.Help()
+			if err != nil {
+				return
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&alias, tagAliasFlagName, "a", "", "a alias to look for a project to manage its tags")
+
+	cmd.AddCommand(tagMigrateCmd())
+
+	return cmd
+}
+
+func tagMigrateCmd() *cobra.Command {
+	var from string
+	var deleteOld, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Re-tag existing releases with the current tag format",
+		Long: `Find the tags of each project written with a previous tag format and create the same releases with the
+current tag_format of the project, pointing to the same commits. The new tags are not pushed.`,
+		Example: "# After setting \"tag_format\": \"{path}/v{version}\" in .version.json, run:\n" +
+			"gommitizen tag migrate --dry-run\n" +
+			"# This will show the tags that would be created from the tags in the old {version}+{alias} format, or\n" +
+			"# {version} for projects without alias.\n\n" +
+			"# To create them and remove the old ones, run:\n" +
+			"gommitizen tag migrate --delete-old\n" +
+			"git push --tags\n",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(from) == 0 {
+				return nil
+			}
+			return config.TagFormat(from).Validate()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			dirPath := cmd.Root().Flag(rootDirPathFlagName).Value.String()
+			alias := cmd.Parent().Flag(tagAliasFlagName).Value.String()
+			tagMigrateRun(git.NewRepository(dirPath), dirPath, alias, config.TagFormat(from), deleteOld, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "the tag format of the existing tags (default \""+config.LegacyTagFormat+"\", or \""+
+		config.TagFormatVersion+"\" for projects without alias)")
+	cmd.Flags().BoolVar(&deleteOld, "delete-old", false, "delete the old tags once the new ones are created")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the tags that would be created without creating them")
+
+	return cmd
+}
+
+func tagMigrateRun(repo git.Repository, dirPath string, alias string, from config.TagFormat, deleteOld bool, dryRun bool) {
+	configVersions, err := findConfigVersions(repo, dirPath, alias)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	migrated, err := migrateTags(repo, configVersions, from, deleteOld, dryRun)
+	if err != nil {
+		slog.Error(fmt.Sprintf("migrate tags: %v", err))
+		os.Exit(1)
+	}
+
+	if dryRun {
+		slog.Info(fmt.Sprintf("Dry run: %d tags would be migrated", migrated))
+	} else {
+		slog.Info(fmt.Sprintf("%d tags migrated, push them with `git push --tags`", migrated))
+	}
+}
+
+// migrateTags creates, for each tag of a project in the from format, the tag of the same version in the current
+// format of the project. An empty from stands for the format a project has without tag_format. Annotated tags are
+// recreated with their message and tagger. It returns the number of tags migrated.
+func migrateTags(repo git.Repository, configVersions []*config.ConfigVersion, from config.TagFormat, deleteOld bool, dryRun bool) (int, error) {
+	tags, err := repo.GetTags()
+	if err != nil {
+		return 0, err
+	}
+	existing := make(map[string]bool)
+	for _, tag := range tags {
+		existing[tag.Name] = true
+	}
+
+	migrated := 0
+	for _, configVersion := range configVersions {
+		projectFrom := from
+		if len(projectFrom) == 0 {
+			projectFrom = config.TagFormatVersion
+			if len(configVersion.Alias) > 0 {
+				projectFrom = config.LegacyTagFormat
+			}
+		}
+		if configVersion.GetTagFormat() == projectFrom {
+			slog.Info(fmt.Sprintf("Skipping project %s, it already uses the tag format %s", configVersion.GetDirPath(), projectFrom))
+			continue
+		}
+
+		for _, tag := range tags {
+			version, ok := projectFrom.Parse(tag.Name, configVersion.Alias, configVersion.GetRelativeDirPath())
+			if !ok {
+				continue
+			}
+
+			newTag := configVersion.GetGitTagForVersion(version)
+			if existing[newTag] {
+				slog.Info(fmt.Sprintf("Skipping %s, tag %s already exists", tag.Name, newTag))
+				continue
+			}
+
+			slog.Info(fmt.Sprintf("%s -> %s (%s)", tag.Name, newTag, tag.Commit))
+			migrated++
+			if dryRun {
+				continue
+			}
+
+			if tag.Annotation != nil {
+				_, err = repo.CreateAnnotatedTagAt(newTag, tag.Commit, *tag.Annotation)
+			} else {
+				_, err = repo.CreateTagAt(newTag, tag.Commit)
+			}
+			if err != nil {
+				return migrated, err
+			}
+			existing[newTag] = true
+
+			if deleteOld {
+				if _, err := repo.DeleteTag(tag.Name); err != nil {
+					return migrated, err
+				}
+			}
+		}
+	}
+
+	return migrated, nil
+}
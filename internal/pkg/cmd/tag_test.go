@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/config"
+	"github.com/freepik-company/gommitizen/internal/app/gommitizen/git"
+)
+
+func TestMigrateTags(t *testing.T) {
+	repo := git.NewFakeRepository()
+	first := repo.AddCommit("chore: init", "chart")
+	second := repo.AddCommit("feat: values", "chart")
+	repo.Tags["1.0.0+chart"] = first
+	repo.Tags["1.1.0+chart"] = second
+	repo.Tags["1.0.0+other"] = first
+	annotation := git.TagAnnotation{Message: "release 1.1.0", TaggerName: "dev", TaggerEmail: "dev@example.com"}
+	repo.Annotations["1.1.0+chart"] = annotation
+
+	cfg := config.NewConfigVersion(t.TempDir(), "1.1.0", second, "chart")
+	cfg.TagFormat = "{alias}-v{version}"
+
+	migrated, err := migrateTags(repo, []*config.ConfigVersion{cfg}, "", true, false)
+	if err != nil {
+		t.Fatalf("migrateTags() error = %v", err)
+	}
+	if migrated != 2 {
+		t.Errorf("expected 2 tags migrated, got %d", migrated)
+	}
+
+	expected := map[string]string{"chart-v1.0.0": first, "chart-v1.1.0": second, "1.0.0+other": first}
+	if len(repo.Tags) != len(expected) {
+		t.Errorf("expected tags %v, got %v", expected, repo.Tags)
+	}
+	for tag, commit := range expected {
+		if repo.Tags[tag] != commit {
+			t.Errorf("expected tag %s at %s, got %s", tag, commit, repo.Tags[tag])
+		}
+	}
+	if _, ok := repo.Annotations["chart-v1.0.0"]; ok {
+		t.Errorf("expected chart-v1.0.0 to stay a lightweight tag")
+	}
+	if repo.Annotations["chart-v1.1.0"] != annotation {
+		t.Errorf("expected chart-v1.1.0 annotated with %+v, got %+v", annotation, repo.Annotations["chart-v1.1.0"])
+	}
+}
+
+func TestMigrateTagsWithoutAlias(t *testing.T) {
+	repo := git.NewFakeRepository()
+	first := repo.AddCommit("chore: init", "app")
+	repo.Tags["1.0.0"] = first
+
+	cfg := config.NewConfigVersion(t.TempDir(), "1.0.0", first, "")
+	cfg.Alias = ""
+	cfg.TagFormat = "v{version}"
+
+	migrated, err := migrateTags(repo, []*config.ConfigVersion{cfg}, "", false, false)
+	if err != nil {
+		t.Fatalf("migrateTags() error = %v", err)
+	}
+	if migrated != 1 || repo.Tags["v1.0.0"] != first {
+		t.Errorf("expected v1.0.0 created from 1.0.0, got %v", repo.Tags)
+	}
+}